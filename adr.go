@@ -0,0 +1,548 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultADRMargin  = 10
+	defaultADRSamples = 10
+
+	// adrStep dB gained by every spreading factor step, adrPowerStep TX
+	// power step in dB.
+	adrStep      = 2.5
+	adrPowerStep = 3
+)
+
+// requiredSNR demodulation floor in dB by spreading factor.
+var requiredSNR = map[int]float64{
+	6: -5, 7: -7.5, 8: -10, 9: -12.5, 10: -15, 11: -17.5, 12: -20,
+}
+
+// sensitivity receiver sensitivity in dBm by spreading factor at 125 kHz,
+// 3 dB worse every time the bandwidth doubles.
+var sensitivity = map[int]float64{
+	6: -118, 7: -123, 8: -126, 9: -129, 10: -132, 11: -134.5, 12: -137,
+}
+
+// ADRConfig configuration of an ADR.
+type ADRConfig struct {
+	// Margin in dB kept above the demodulation floor, defaults to 10.
+	Margin float64
+	// Hysteresis in dB of extra margin needed before lowering the
+	// spreading factor or the power, so the link doesn't flap between
+	// two settings.
+	Hysteresis float64
+	// Samples frames observed before deciding, defaults to 10.
+	Samples int
+	// MinSpreadingFactor and MaxSpreadingFactor bound the spreading
+	// factor, default to 7 and 12.
+	MinSpreadingFactor int
+	MaxSpreadingFactor int
+	// AdaptPower lowers the TX power, between MinPower and MaxPower, once
+	// the lowest spreading factor is reached.
+	AdaptPower bool
+	// MinPower and MaxPower in dBm, default to 5 and 20.
+	MinPower int
+	MaxPower int
+}
+
+// ADR client-side link adaptation for LoraP2P and unmanaged links, where
+// no network server adapts the data rate. It observes the signal of the
+// frames received and picks the lowest spreading factor, then power, that
+// keeps the margin, assuming a symmetric link.
+type ADR struct {
+	conf ADRConfig
+
+	mu      sync.Mutex
+	samples []Signal
+}
+
+// NewADR creates an ADR with no observations.
+func NewADR(conf ADRConfig) *ADR {
+	if conf.Margin == 0 {
+		conf.Margin = defaultADRMargin
+	}
+	if conf.Samples <= 0 {
+		conf.Samples = defaultADRSamples
+	}
+	if conf.MinSpreadingFactor == 0 {
+		conf.MinSpreadingFactor = 7
+	}
+	if conf.MaxSpreadingFactor == 0 {
+		conf.MaxSpreadingFactor = 12
+	}
+	if conf.MinPower == 0 {
+		conf.MinPower = 5
+	}
+	if conf.MaxPower == 0 {
+		conf.MaxPower = 20
+	}
+	return &ADR{conf: conf}
+}
+
+// Observe records the signal of a frame received, the oldest samples are
+// dropped.
+func (a *ADR) Observe(s Signal) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.samples = append(a.samples, s)
+	if len(a.samples) > a.conf.Samples {
+		a.samples = a.samples[len(a.samples)-a.conf.Samples:]
+	}
+}
+
+// ObserveFrame records the signal of the frame, frames without RSSI and
+// SNR are ignored.
+func (a *ADR) ObserveFrame(f Frame) {
+	if f.RSSI == 0 && f.SNR == 0 {
+		return
+	}
+	a.Observe(Signal{RSSI: f.RSSI, SNR: f.SNR})
+}
+
+// Reset drops the samples, they no longer apply once the config changed.
+func (a *ADR) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.samples = nil
+}
+
+// Recommend returns the config to use given the samples observed with
+// the current config, and whether it differs. Nothing changes until
+// enough samples are observed.
+func (a *ADR) Recommend(current RFConfig) (RFConfig, bool) {
+	a.mu.Lock()
+	samples := append([]Signal(nil), a.samples...)
+	a.mu.Unlock()
+
+	if len(samples) < a.conf.Samples {
+		return current, false
+	}
+	margin, ok := linkMargin(current, samples)
+	if !ok {
+		return current, false
+	}
+	margin -= a.conf.Margin
+
+	next := current
+	switch {
+	case margin < 0:
+		a.stepUp(&next, int(math.Ceil(-margin/adrStep)))
+	case margin >= adrStep+a.conf.Hysteresis:
+		a.stepDown(&next, int(math.Floor((margin-a.conf.Hysteresis)/adrStep)))
+	}
+	return next, next != current
+}
+
+// stepDown lowers the spreading factor, then the power, by steps.
+func (a *ADR) stepDown(c *RFConfig, steps int) {
+	for ; steps > 0 && c.SpreadingFactor > a.conf.MinSpreadingFactor; steps-- {
+		c.SpreadingFactor--
+	}
+	if !a.conf.AdaptPower {
+		return
+	}
+	// a power step is worth about one spreading factor step
+	for ; steps > 0 && c.Power-adrPowerStep >= a.conf.MinPower; steps-- {
+		c.Power -= adrPowerStep
+	}
+}
+
+// stepUp raises the power, then the spreading factor, by steps.
+func (a *ADR) stepUp(c *RFConfig, steps int) {
+	if a.conf.AdaptPower {
+		for ; steps > 0 && c.Power < a.conf.MaxPower; steps-- {
+			c.Power = min(c.Power+adrPowerStep, a.conf.MaxPower)
+		}
+	}
+	for ; steps > 0 && c.SpreadingFactor < a.conf.MaxSpreadingFactor; steps-- {
+		c.SpreadingFactor++
+	}
+}
+
+// linkMargin returns the margin in dB of the best samples above the
+// demodulation floor and the receiver sensitivity of the config.
+func linkMargin(c RFConfig, samples []Signal) (float64, bool) {
+	snrFloor, ok := requiredSNR[c.SpreadingFactor]
+	if !ok || c.Bandwidth.Hz() == 0 {
+		return 0, false
+	}
+	rssiFloor := sensitivity[c.SpreadingFactor] + 10*math.Log10(float64(c.Bandwidth.Hz())/125000)
+
+	snr, rssi := math.Inf(-1), math.Inf(-1)
+	for _, s := range samples {
+		snr = math.Max(snr, float64(s.SNR))
+		rssi = math.Max(rssi, float64(s.RSSI))
+	}
+	return math.Min(snr-snrFloor, rssi-rssiFloor), true
+}
+
+// Adapt samples the signal of the last packet received by the module and
+// applies the recommended config with SetRfConfig, for links driven with
+// Txc and Rxc. Returns the config in effect. Only the local radio
+// changes: the peers must switch to the same spreading factor and
+// bandwidth, or the link is lost.
+func (a *ADR) Adapt(l *Lora, current RFConfig) (RFConfig, error) {
+	resp, err := l.Signal()
+	if err != nil {
+		return current, err
+	}
+	if err := isError(resp); err != nil {
+		return current, err
+	}
+	s, err := ParseSignal(resp)
+	if err != nil {
+		return current, err
+	}
+	a.Observe(s)
+
+	next, ok := a.Recommend(current)
+	if !ok {
+		return current, nil
+	}
+	resp, err = l.SetRfConfig(next.String())
+	if err != nil {
+		return current, err
+	}
+	if err := isError(resp); err != nil {
+		return current, err
+	}
+	a.Reset()
+	return next, nil
+}
+
+// AdaptP2P applies the config recommended from the frames observed to
+// the link. Returns whether it changed. Only the local radio changes, so
+// the peer must take the same decision at the same time; use an ADRLink
+// to switch both ends together.
+func (a *ADR) AdaptP2P(ctx context.Context, p *P2P) (bool, error) {
+	next, ok := a.Recommend(p.Config())
+	if !ok {
+		return false, nil
+	}
+	if err := p.SetConfig(ctx, next); err != nil {
+		return false, fmt.Errorf("failed to apply rf config: %v", err)
+	}
+	a.Reset()
+	return true, nil
+}
+
+const (
+	// ADRHeaderSize byte prepended to every frame of an ADRLink: type.
+	ADRHeaderSize = 1
+
+	adrData      = 0x01
+	adrAnnounce  = 0x02
+	adrAck       = 0x03
+	adrConfirm   = 0x04
+	adrConfirmed = 0x05
+
+	// adrConfirmAttempts confirms sent within the timeout before going
+	// back to the previous modulation.
+	adrConfirmAttempts = 3
+
+	defaultADRSwitchTimeout = 5 * time.Second
+	defaultADRSwitchDelay   = 500 * time.Millisecond
+)
+
+// ErrNoSwitch the peer didn't switch to the new modulation, the link is
+// back to the previous one.
+var ErrNoSwitch = errors.New("peer didn't switch modulation")
+
+// ConfigurableLink link whose RF config can be changed, as P2P.
+type ConfigurableLink interface {
+	Link
+	// Config returns the RF config of the link.
+	Config() RFConfig
+	// SetConfig applies the RF config to the link.
+	SetConfig(ctx context.Context, config RFConfig) error
+}
+
+// ADRLinkConfig configuration of an ADRLink, zero values use the defaults.
+type ADRLinkConfig struct {
+	// Timeout wait for the peer on the new modulation before going back to
+	// the previous one, defaults to 5s.
+	Timeout time.Duration
+	// SwitchDelay wait of the peer before acknowledging a new modulation,
+	// the time given to the sender to switch, defaults to 500ms.
+	SwitchDelay time.Duration
+}
+
+func (c ADRLinkConfig) withDefaults() ADRLinkConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultADRSwitchTimeout
+	}
+	if c.SwitchDelay <= 0 {
+		c.SwitchDelay = defaultADRSwitchDelay
+	}
+	return c
+}
+
+// ADRLink adapts a link to a single peer, which must use an ADRLink too.
+// Both ends must share the spreading factor, bandwidth and coding rate,
+// so changing them with Adapt is coordinated: the new modulation is
+// announced on the current one, the peer switches and acknowledges on the
+// new one, then the switch is confirmed until the peer answers. Either
+// end goes back to the previous modulation if it doesn't hear the other
+// within the timeout, so the ends only disagree when every answer to the
+// confirms is lost. The TX power is local and changed without
+// coordination.
+type ADRLink struct {
+	link ConfigurableLink
+	adr  *ADR
+	conf ADRLinkConfig
+
+	frames chan Frame
+
+	// adapt serialises the adaptations
+	adapt sync.Mutex
+
+	mu        sync.Mutex
+	acked     chan RFConfig
+	confirmed chan RFConfig
+	revert    *time.Timer
+}
+
+// NewADRLink starts observing the frames received on link with adr.
+func NewADRLink(link ConfigurableLink, adr *ADR, conf ADRLinkConfig) *ADRLink {
+	a := &ADRLink{
+		link:   link,
+		adr:    adr,
+		conf:   conf.withDefaults(),
+		frames: make(chan Frame, frameBacklog),
+	}
+	go a.run()
+	return a
+}
+
+// Transmit sends the payload in a single frame.
+func (a *ADRLink) Transmit(ctx context.Context, payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyPayload
+	}
+	return a.link.Transmit(ctx, append([]byte{adrData}, payload...))
+}
+
+// Receive returns the channel where received frames are delivered,
+// without the ADR header. The channel is closed when the underlying link
+// receive channel is closed.
+func (a *ADRLink) Receive() <-chan Frame {
+	return a.frames
+}
+
+// Adapt applies the config recommended by the ADR, switching the peer to
+// the new modulation first. Returns whether it changed, ErrNoSwitch when
+// the peer didn't switch.
+func (a *ADRLink) Adapt(ctx context.Context) (bool, error) {
+	a.adapt.Lock()
+	defer a.adapt.Unlock()
+
+	current := a.link.Config()
+	next, ok := a.adr.Recommend(current)
+	if !ok {
+		return false, nil
+	}
+	if sameModulation(current, next) {
+		if err := a.link.SetConfig(ctx, next); err != nil {
+			return false, fmt.Errorf("failed to apply rf config: %v", err)
+		}
+		a.adr.Reset()
+		return true, nil
+	}
+
+	acked, confirmed := make(chan RFConfig, 1), make(chan RFConfig, 1)
+	a.mu.Lock()
+	a.acked, a.confirmed = acked, confirmed
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.acked, a.confirmed = nil, nil
+		a.mu.Unlock()
+	}()
+
+	if err := a.link.Transmit(ctx, modulationFrame(adrAnnounce, next)); err != nil {
+		return false, err
+	}
+	if err := a.link.SetConfig(ctx, next); err != nil {
+		return false, fmt.Errorf("failed to apply rf config: %v", err)
+	}
+
+	if a.wait(ctx, acked, next, a.conf.Timeout) && a.confirm(ctx, confirmed, next) {
+		a.adr.Reset()
+		return true, nil
+	}
+
+	if err := a.link.SetConfig(context.Background(), current); err != nil {
+		return false, fmt.Errorf("failed to restore rf config: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return false, ErrNoSwitch
+}
+
+// confirm confirms the switch to next until the peer answers, which it
+// does with the modulation kept, or the timeout after which the peer goes
+// back to the previous modulation.
+func (a *ADRLink) confirm(ctx context.Context, confirmed <-chan RFConfig, next RFConfig) bool {
+	for i := 0; i < adrConfirmAttempts; i++ {
+		if err := a.link.Transmit(ctx, []byte{adrConfirm}); err != nil {
+			return false
+		}
+		if a.wait(ctx, confirmed, next, a.conf.Timeout/adrConfirmAttempts) {
+			return true
+		}
+	}
+	return false
+}
+
+// wait waits for the peer to report the modulation of next on ch.
+func (a *ADRLink) wait(ctx context.Context, ch <-chan RFConfig, next RFConfig, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case c := <-ch:
+			if sameModulation(c, next) {
+				return true
+			}
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (a *ADRLink) run() {
+	defer close(a.frames)
+
+	for frame := range a.link.Receive() {
+		if len(frame.Payload) < ADRHeaderSize {
+			continue
+		}
+		a.adr.ObserveFrame(frame)
+		// the peer is heard on the new modulation
+		a.keep()
+
+		switch frame.Payload[0] {
+		case adrData:
+			frame.Payload = frame.Payload[ADRHeaderSize:]
+			select {
+			case a.frames <- frame:
+			default:
+			}
+		case adrAnnounce:
+			if c, ok := parseModulationFrame(frame.Payload); ok {
+				a.follow(c)
+			}
+		case adrAck:
+			if c, ok := parseModulationFrame(frame.Payload); ok {
+				a.mu.Lock()
+				notify(a.acked, c)
+				a.mu.Unlock()
+			}
+		case adrConfirm:
+			// the revert was cancelled by the frame, the peer is told
+			_ = a.link.Transmit(context.Background(), modulationFrame(adrConfirmed, a.link.Config()))
+		case adrConfirmed:
+			if c, ok := parseModulationFrame(frame.Payload); ok {
+				a.mu.Lock()
+				notify(a.confirmed, c)
+				a.mu.Unlock()
+			}
+		}
+	}
+}
+
+// notify passes c to ch without blocking, a nil ch drops it.
+func notify(ch chan RFConfig, c RFConfig) {
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- c:
+	default:
+	}
+}
+
+// follow switches to the modulation announced by the peer and
+// acknowledges it after the switch delay, going back to the previous one
+// unless the peer is heard before the timeout.
+func (a *ADRLink) follow(m RFConfig) {
+	previous := a.link.Config()
+	next := previous
+	next.SpreadingFactor, next.Bandwidth, next.CodingRate = m.SpreadingFactor, m.Bandwidth, m.CodingRate
+	if err := next.Validate(); err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	if err := a.link.SetConfig(ctx, next); err != nil {
+		return
+	}
+	a.adr.Reset()
+
+	a.mu.Lock()
+	if a.revert != nil {
+		a.revert.Stop()
+	}
+	var revert *time.Timer
+	revert = time.AfterFunc(a.conf.SwitchDelay+a.conf.Timeout, func() {
+		a.mu.Lock()
+		if a.revert != revert {
+			a.mu.Unlock()
+			return
+		}
+		a.revert = nil
+		a.mu.Unlock()
+		_ = a.link.SetConfig(ctx, previous)
+	})
+	a.revert = revert
+	a.mu.Unlock()
+
+	// the receptions go on while the sender switches
+	time.AfterFunc(a.conf.SwitchDelay, func() {
+		_ = a.link.Transmit(ctx, modulationFrame(adrAck, next))
+	})
+}
+
+// keep keeps the modulation followed, the peer was heard with it.
+func (a *ADRLink) keep() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.revert != nil {
+		a.revert.Stop()
+		a.revert = nil
+	}
+}
+
+// sameModulation reports whether both configs can hear each other.
+func sameModulation(a, b RFConfig) bool {
+	return a.SpreadingFactor == b.SpreadingFactor && a.Bandwidth == b.Bandwidth && a.CodingRate == b.CodingRate
+}
+
+// modulationFrame control frame of type carrying the modulation of c.
+func modulationFrame(typ byte, c RFConfig) []byte {
+	return []byte{typ, byte(c.SpreadingFactor), byte(c.Bandwidth), byte(c.CodingRate)}
+}
+
+func parseModulationFrame(payload []byte) (RFConfig, bool) {
+	if len(payload) != 4 {
+		return RFConfig{}, false
+	}
+	return RFConfig{
+		SpreadingFactor: int(payload[1]),
+		Bandwidth:       Bandwidth(payload[2]),
+		CodingRate:      CodingRate(payload[3]),
+	}, true
+}
@@ -0,0 +1,253 @@
+package rak811
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestADR_Recommend(t *testing.T) {
+	sf := func(sf, power int) RFConfig {
+		c := DefaultRFConfig
+		c.SpreadingFactor, c.Power = sf, power
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		conf   ADRConfig
+		signal Signal
+		in     RFConfig
+		want   RFConfig
+	}{
+		{"strong link", ADRConfig{}, Signal{RSSI: -90, SNR: 5}, sf(12, 20), sf(7, 20)},
+		{"strong link power", ADRConfig{AdaptPower: true}, Signal{RSSI: -90, SNR: 5}, sf(12, 20), sf(7, 17)},
+		{"weak link", ADRConfig{}, Signal{RSSI: -120, SNR: -10}, sf(7, 14), sf(12, 14)},
+		{"weak link power", ADRConfig{AdaptPower: true}, Signal{RSSI: -120, SNR: -10}, sf(7, 14), sf(10, 20)},
+		{"within margin", ADRConfig{}, Signal{RSSI: -100, SNR: -1}, sf(9, 14), sf(9, 14)},
+		{"one step", ADRConfig{}, Signal{RSSI: -100, SNR: 1}, sf(9, 14), sf(8, 14)},
+		{"hysteresis", ADRConfig{Hysteresis: 3}, Signal{RSSI: -100, SNR: 1}, sf(9, 14), sf(9, 14)},
+		{"rssi bound", ADRConfig{}, Signal{RSSI: -125, SNR: 5}, sf(12, 20), sf(12, 20)},
+		{"bounds", ADRConfig{MinSpreadingFactor: 9}, Signal{RSSI: -90, SNR: 5}, sf(12, 20), sf(9, 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewADR(tt.conf)
+			for i := 0; i < defaultADRSamples; i++ {
+				if got, ok := a.Recommend(tt.in); ok {
+					t.Fatalf("got %+v after %d samples", got, i)
+				}
+				a.Observe(tt.signal)
+			}
+			got, changed := a.Recommend(tt.in)
+			if got != tt.want || changed != (tt.in != tt.want) {
+				t.Errorf("got %+v, %v, want %+v", got, changed, tt.want)
+			}
+		})
+	}
+}
+
+func TestADR_ObserveFrame(t *testing.T) {
+	a := NewADR(ADRConfig{Samples: 2})
+	a.ObserveFrame(Frame{RSSI: -60, SNR: 5})
+	a.ObserveFrame(Frame{Payload: []byte{1}})
+	if _, ok := a.Recommend(DefaultRFConfig); ok {
+		t.Error("frames without signal must be ignored")
+	}
+	a.ObserveFrame(Frame{RSSI: -60, SNR: 5})
+	if _, ok := a.Recommend(DefaultRFConfig); !ok {
+		t.Error("want a new config")
+	}
+	a.Reset()
+	if _, ok := a.Recommend(DefaultRFConfig); ok {
+		t.Error("want no config after reset")
+	}
+}
+
+func TestADR_Adapt(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	a := NewADR(ADRConfig{Samples: 1})
+	got, err := a.Adapt(l, DefaultRFConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SpreadingFactor != 7 {
+		t.Errorf("got %+v", got)
+	}
+	want := []string{"signal", "rf_config=868100000,7,0,1,8,20"}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
+
+func TestADR_AdaptP2P(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	conf := DefaultRFConfig
+	conf.Frequency = 869525000
+	p, err := NewP2P(l, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := NewADR(ADRConfig{Samples: 3})
+	for i := 0; i < 3; i++ {
+		module.Emit("at+recv=0,0,-70,4,1:01")
+		select {
+		case f := <-p.Receive():
+			a.ObserveFrame(f)
+		case <-ctx.Done():
+			t.Fatal("no frame")
+		}
+	}
+
+	changed, err := a.AdaptP2P(ctx, p)
+	if err != nil || !changed {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+	if got := p.Config(); got.SpreadingFactor != 7 || got.Frequency != 869525000 {
+		t.Errorf("got %+v", got)
+	}
+	want := []string{"mode=1", "rf_config=869525000,12,0,1,8,20", "rxc=1", "rx_stop", "rf_config=869525000,7,0,1,8,20", "rxc=1"}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
+
+func TestADRLink_Adapt(t *testing.T) {
+	slow := DefaultRFConfig
+	slow.SpreadingFactor = 12
+
+	medium := newMemMedium(nil)
+	linkA, linkB := medium.Link(), medium.Link()
+	_ = linkA.SetConfig(context.Background(), slow)
+	_ = linkB.SetConfig(context.Background(), slow)
+
+	conf := ADRLinkConfig{Timeout: 200 * time.Millisecond, SwitchDelay: 10 * time.Millisecond}
+	adr := NewADR(ADRConfig{Samples: 2})
+	a := NewADRLink(linkA, adr, conf)
+	b := NewADRLink(linkB, NewADR(ADRConfig{}), conf)
+
+	adr.Observe(Signal{RSSI: -90, SNR: 5})
+	adr.Observe(Signal{RSSI: -90, SNR: 5})
+	changed, err := a.Adapt(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+	if sf := linkA.Config().SpreadingFactor; sf != 7 {
+		t.Errorf("got SF%d", sf)
+	}
+
+	// the peer keeps the new modulation past the timeout
+	time.Sleep(2 * conf.Timeout)
+	if sf := linkB.Config().SpreadingFactor; sf != 7 {
+		t.Errorf("got peer SF%d", sf)
+	}
+
+	_ = a.Transmit(context.Background(), []byte("ping"))
+	if frame := receiveFrame(t, b.Receive()); string(frame.Payload) != "ping" {
+		t.Errorf("got %q", frame.Payload)
+	}
+	_ = b.Transmit(context.Background(), []byte("pong"))
+	if frame := receiveFrame(t, a.Receive()); string(frame.Payload) != "pong" {
+		t.Errorf("got %q", frame.Payload)
+	}
+}
+
+func TestADRLink_AdaptConfirmLost(t *testing.T) {
+	slow := DefaultRFConfig
+	slow.SpreadingFactor = 12
+	conf := ADRLinkConfig{Timeout: 300 * time.Millisecond, SwitchDelay: 10 * time.Millisecond}
+
+	// announce, ack, then the first confirm is lost and sent again
+	medium := newMemMedium(func(n int) bool { return n == 3 })
+	linkA, linkB := medium.Link(), medium.Link()
+	_ = linkA.SetConfig(context.Background(), slow)
+	_ = linkB.SetConfig(context.Background(), slow)
+
+	adr := NewADR(ADRConfig{Samples: 1})
+	a := NewADRLink(linkA, adr, conf)
+	b := NewADRLink(linkB, NewADR(ADRConfig{}), conf)
+	adr.Observe(Signal{RSSI: -90, SNR: 5})
+
+	if changed, err := a.Adapt(context.Background()); err != nil || !changed {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+	time.Sleep(2 * conf.Timeout)
+	if sfA, sfB := linkA.Config().SpreadingFactor, linkB.Config().SpreadingFactor; sfA != 7 || sfB != 7 {
+		t.Errorf("got SF%d and peer SF%d", sfA, sfB)
+	}
+	_ = a.Transmit(context.Background(), []byte("ping"))
+	if frame := receiveFrame(t, b.Receive()); string(frame.Payload) != "ping" {
+		t.Errorf("got %q", frame.Payload)
+	}
+}
+
+func TestADRLink_AdaptConfirmsLost(t *testing.T) {
+	slow := DefaultRFConfig
+	slow.SpreadingFactor = 12
+	conf := ADRLinkConfig{Timeout: 300 * time.Millisecond, SwitchDelay: 10 * time.Millisecond}
+
+	// every confirm is lost, both ends go back to the previous modulation
+	var lossy atomic.Bool
+	lossy.Store(true)
+	medium := newMemMedium(func(n int) bool { return n >= 3 && lossy.Load() })
+	linkA, linkB := medium.Link(), medium.Link()
+	_ = linkA.SetConfig(context.Background(), slow)
+	_ = linkB.SetConfig(context.Background(), slow)
+
+	adr := NewADR(ADRConfig{Samples: 1})
+	a := NewADRLink(linkA, adr, conf)
+	b := NewADRLink(linkB, NewADR(ADRConfig{}), conf)
+	adr.Observe(Signal{RSSI: -90, SNR: 5})
+
+	if changed, err := a.Adapt(context.Background()); err != ErrNoSwitch || changed {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+	time.Sleep(2 * conf.Timeout)
+	lossy.Store(false)
+	if sfA, sfB := linkA.Config().SpreadingFactor, linkB.Config().SpreadingFactor; sfA != 12 || sfB != 12 {
+		t.Errorf("got SF%d and peer SF%d", sfA, sfB)
+	}
+	_ = a.Transmit(context.Background(), []byte("ping"))
+	if frame := receiveFrame(t, b.Receive()); string(frame.Payload) != "ping" {
+		t.Errorf("got %q", frame.Payload)
+	}
+}
+
+func TestADRLink_AdaptNoPeer(t *testing.T) {
+	slow := DefaultRFConfig
+	slow.SpreadingFactor = 12
+
+	medium := newMemMedium(nil)
+	linkA, peer := medium.Link(), medium.Link()
+	_ = linkA.SetConfig(context.Background(), slow)
+	_ = peer.SetConfig(context.Background(), slow)
+
+	adr := NewADR(ADRConfig{Samples: 1})
+	a := NewADRLink(linkA, adr, ADRLinkConfig{Timeout: 50 * time.Millisecond})
+	adr.Observe(Signal{RSSI: -90, SNR: 5})
+
+	// the peer doesn't switch, the link stays on the previous modulation
+	if changed, err := a.Adapt(context.Background()); err != ErrNoSwitch || changed {
+		t.Fatalf("got %v, %v", changed, err)
+	}
+	if c := linkA.Config(); c != slow {
+		t.Errorf("got %+v", c)
+	}
+	_ = peer.Transmit(context.Background(), []byte{adrData, 1})
+	if frame := receiveFrame(t, a.Receive()); frame.Payload[0] != 1 {
+		t.Errorf("got %v", frame.Payload)
+	}
+}
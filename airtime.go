@@ -0,0 +1,94 @@
+package rak811
+
+import (
+	"math"
+	"time"
+)
+
+// lorawanOverhead bytes added to the application payload by the MAC
+// header, frame header without options, port and MIC.
+const lorawanOverhead = 13
+
+// Modulation LoRa parameters determining the airtime of a frame. Frames
+// always carry a payload CRC, as uplinks and LoraP2P frames do.
+type Modulation struct {
+	// SpreadingFactor 6 to 12, SF6 requires an implicit header.
+	SpreadingFactor int
+	Bandwidth       Bandwidth
+	CodingRate      CodingRate
+	// Preamble length in symbols.
+	Preamble int
+	// ExplicitHeader the frame starts with a header giving its length
+	// and coding rate, as LoRaWAN and LoraP2P frames do.
+	ExplicitHeader bool
+	// LowDataRateOptimize mandated for symbols of 16ms and longer, see
+	// NewModulation.
+	LowDataRateOptimize bool
+}
+
+// NewModulation returns the modulation with an explicit header, enabling
+// the low data rate optimisation when mandated.
+func NewModulation(spreadingFactor int, bandwidth Bandwidth, codingRate CodingRate, preamble int) Modulation {
+	m := Modulation{
+		SpreadingFactor: spreadingFactor,
+		Bandwidth:       bandwidth,
+		CodingRate:      codingRate,
+		Preamble:        preamble,
+		ExplicitHeader:  true,
+	}
+	m.LowDataRateOptimize = m.Symbol() >= 16*time.Millisecond
+	return m
+}
+
+// Symbol returns the duration of a symbol, zero for an invalid bandwidth.
+func (m Modulation) Symbol() time.Duration {
+	if m.Bandwidth.Hz() == 0 {
+		return 0
+	}
+	return time.Duration(math.Exp2(float64(m.SpreadingFactor)) * float64(time.Second) / float64(m.Bandwidth.Hz()))
+}
+
+// TimeOnAir returns the airtime of a frame of size bytes, as given by
+// the Semtech SX1272/3/6/7/8 LoRa modem designer's guide.
+func TimeOnAir(m Modulation, size int) time.Duration {
+	if m.Bandwidth.Hz() == 0 {
+		return 0
+	}
+	sf := float64(m.SpreadingFactor)
+	symbol := math.Exp2(sf) / float64(m.Bandwidth.Hz())
+
+	ih, de := 1.0, 0.0
+	if m.ExplicitHeader {
+		ih = 0
+	}
+	if m.LowDataRateOptimize {
+		de = 1
+	}
+
+	bits := 8*float64(size) - 4*sf + 28 + 16 - 20*ih
+	symbols := 8 + math.Max(math.Ceil(bits/(4*(sf-2*de)))*float64(m.CodingRate+4), 0)
+
+	seconds := (float64(m.Preamble)+4.25)*symbol + symbols*symbol
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Modulation returns the modulation of the RF config.
+func (c RFConfig) Modulation() Modulation {
+	return NewModulation(c.SpreadingFactor, c.Bandwidth, c.CodingRate, c.Preamble)
+}
+
+// TimeOnAir returns the airtime of a LoraP2P frame of size bytes.
+func (c RFConfig) TimeOnAir(size int) time.Duration {
+	return TimeOnAir(c.Modulation(), size)
+}
+
+// LoRaWANTimeOnAir returns the airtime of an uplink of the region at the
+// data rate, size being the application payload without the 13 bytes of
+// LoRaWAN overhead.
+func LoRaWANTimeOnAir(region Region, dr, size int) (time.Duration, error) {
+	rate, err := region.DataRate(dr)
+	if err != nil {
+		return 0, err
+	}
+	return TimeOnAir(rate.Modulation(), size+lorawanOverhead), nil
+}
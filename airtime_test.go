@@ -0,0 +1,81 @@
+package rak811
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeOnAir(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Modulation
+		size int
+		want time.Duration
+	}{
+		{"SF7", NewModulation(7, BW125, CR4_5, 8), 13, 46336 * time.Microsecond},
+		{"SF12", NewModulation(12, BW125, CR4_5, 8), 13, 1155072 * time.Microsecond},
+		{"SF9", NewModulation(9, BW125, CR4_5, 8), 20, 185344 * time.Microsecond},
+		{"SF7 250kHz", NewModulation(7, BW250, CR4_5, 8), 13, 23168 * time.Microsecond},
+		{"CR 4/8", NewModulation(7, BW125, CR4_8, 8), 13, 61696 * time.Microsecond},
+		{"low data rate", NewModulation(12, BW125, CR4_5, 8), 51, 2465792 * time.Microsecond},
+		{"no low data rate", Modulation{SpreadingFactor: 12, Bandwidth: BW125, CodingRate: CR4_5, Preamble: 8, ExplicitHeader: true}, 51, 2138112 * time.Microsecond},
+		{"implicit header", Modulation{SpreadingFactor: 6, Bandwidth: BW125, CodingRate: CR4_5, Preamble: 8}, 10, 20608 * time.Microsecond},
+		{"invalid bandwidth", Modulation{SpreadingFactor: 7, Bandwidth: 3}, 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TimeOnAir(tt.m, tt.size)
+			if diff := got - tt.want; diff < -time.Microsecond || diff > time.Microsecond {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewModulation(t *testing.T) {
+	if m := NewModulation(11, BW125, CR4_5, 8); !m.LowDataRateOptimize || !m.ExplicitHeader {
+		t.Errorf("got %+v", m)
+	}
+	if m := NewModulation(11, BW250, CR4_5, 8); m.LowDataRateOptimize {
+		t.Errorf("got %+v", m)
+	}
+	if s := NewModulation(7, BW125, CR4_5, 8).Symbol(); s != 1024*time.Microsecond {
+		t.Errorf("got symbol %s", s)
+	}
+}
+
+func TestRFConfig_TimeOnAir(t *testing.T) {
+	if got := DefaultRFConfig.TimeOnAir(13); got != 1155072*time.Microsecond {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestLoRaWANTimeOnAir(t *testing.T) {
+	tests := []struct {
+		region Region
+		dr     int
+		want   time.Duration
+	}{
+		{EU868, 5, 46336 * time.Microsecond},
+		{EU868, 0, 1155072 * time.Microsecond},
+		{US915, 4, 20608 * time.Microsecond},
+	}
+
+	for _, tt := range tests {
+		got, err := LoRaWANTimeOnAir(tt.region, tt.dr, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := got - tt.want; diff < -time.Microsecond || diff > time.Microsecond {
+			t.Errorf("%s DR%d: got %s, want %s", tt.region, tt.dr, got, tt.want)
+		}
+	}
+
+	if _, err := LoRaWANTimeOnAir(EU868, 7, 0); err == nil {
+		t.Error("want error for an unknown data rate")
+	}
+	if _, err := LoRaWANTimeOnAir(Region("XX000"), 0, 0); err == nil {
+		t.Error("want error for an unknown band")
+	}
+}
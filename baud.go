@@ -0,0 +1,105 @@
+package rak811
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tarm/serial"
+)
+
+// probeReadTimeout serial read timeout while probing, so the deadline
+// is checked often.
+const probeReadTimeout = 100 * time.Millisecond
+
+// probeTimeout how long to wait for the module to answer a probe.
+var probeTimeout = time.Second
+
+// DefaultBaudRates baud rates tried by Probe, in order.
+var DefaultBaudRates = []int{115200, 9600, 57600, 38400, 19200, 230400, 4800}
+
+// ErrNoResponse the module didn't answer at any of the baud rates.
+var ErrNoResponse = errors.New("no response from module")
+
+// openSerial opens the serial port, replaced in tests.
+var openSerial = func(c *serial.Config) (io.ReadWriteCloser, error) {
+	return serial.OpenPort(c)
+}
+
+// Probe detects the baud rate of the module connected to the serial port
+// name by sending a version command at every rate in DefaultBaudRates.
+func Probe(name string) (int, error) {
+	return probe(&Config{
+		Name:     name,
+		Parity:   ParityNone,
+		StopBits: Stop1,
+		Size:     8,
+	}, DefaultBaudRates)
+}
+
+func probe(conf *Config, rates []int) (int, error) {
+	for _, baud := range rates {
+		ok, err := probeBaud(conf, baud)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return baud, nil
+		}
+	}
+	return 0, ErrNoResponse
+}
+
+// probeBaud reports whether the module answers at+version at baud.
+func probeBaud(conf *Config, baud int) (bool, error) {
+	p, err := openSerial(&serial.Config{
+		Name:        conf.Name,
+		Baud:        baud,
+		ReadTimeout: probeReadTimeout,
+		Size:        conf.Size,
+		Parity:      serial.Parity(conf.Parity),
+		StopBits:    serial.StopBits(conf.StopBits),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s at %d: %v", conf.Name, baud, err)
+	}
+	defer p.Close()
+
+	if _, err := p.Write(createCmd("version")); err != nil {
+		return false, fmt.Errorf("failed to write probe at %d: %v", baud, err)
+	}
+
+	lines := newLineScanner(p)
+	deadline := time.Now().Add(probeTimeout)
+	for time.Now().Before(deadline) {
+		line, err := lines.next()
+		if err != nil {
+			if err == io.EOF || err == errLineTooLong {
+				continue
+			}
+			return false, nil
+		}
+		if isOk(strings.TrimSpace(line)) {
+			return true, nil
+		}
+	}
+	return isOk(strings.TrimSpace(lines.pending())), nil
+}
+
+// baudRates returns the rates to probe, starting with the configured one.
+func baudRates(conf *Config) []int {
+	rates := conf.BaudRates
+	if len(rates) == 0 {
+		rates = DefaultBaudRates
+	}
+
+	ordered := []int{conf.Baud}
+	for _, rate := range rates {
+		if rate != conf.Baud {
+			ordered = append(ordered, rate)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,69 @@
+package rak811
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tarm/serial"
+)
+
+func TestProbe(t *testing.T) {
+	defer fakeBaud(9600)()
+
+	baud, err := Probe("/dev/ttyUSB0")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if baud != 9600 {
+		t.Errorf("got %d, want 9600", baud)
+	}
+}
+
+func TestProbe_NoResponse(t *testing.T) {
+	defer fakeBaud(1200)()
+
+	if _, err := Probe("/dev/ttyUSB0"); err != ErrNoResponse {
+		t.Errorf("got %v, want %v", err, ErrNoResponse)
+	}
+}
+
+func TestNew_AutoBaud(t *testing.T) {
+	defer fakeBaud(57600)()
+
+	var opened []int
+	probeOpen := openSerial
+	openSerial = func(c *serial.Config) (io.ReadWriteCloser, error) {
+		opened = append(opened, c.Baud)
+		return probeOpen(c)
+	}
+
+	lora, err := New(&Config{Name: "/dev/ttyUSB0", AutoBaud: true, BaudRates: []int{9600, 57600}})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer lora.Close()
+
+	// probes 115200, 9600 and 57600, then opens at 57600
+	if len(opened) != 4 || opened[3] != 57600 {
+		t.Errorf("got %v, want port opened at 57600", opened)
+	}
+}
+
+// fakeBaud replaces the serial port with a module answering only at
+// baud, returns a function restoring the real one.
+func fakeBaud(baud int) func() {
+	open, timeout := openSerial, probeTimeout
+	probeTimeout = 20 * time.Millisecond
+	openSerial = func(c *serial.Config) (io.ReadWriteCloser, error) {
+		if c.Name != "/dev/ttyUSB0" {
+			return nil, errors.New("no such file or directory")
+		}
+		if c.Baud == baud {
+			return newFakeSerialConn([]byte("OK2.0.3.0\r\n")), nil
+		}
+		return newFakeSerialConn([]byte{0xf0, 0x0f, 0x80}), nil
+	}
+	return func() { openSerial, probeTimeout = open, timeout }
+}
@@ -0,0 +1,260 @@
+package rak811
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// SchemeReplay replays a capture file as a fake module, e.g.
+	// replay://testdata/join.jsonl.
+	SchemeReplay = "replay"
+
+	// CaptureTx data written to the module.
+	CaptureTx = "tx"
+	// CaptureRx data read from the module.
+	CaptureRx = "rx"
+)
+
+// CaptureRecord a chunk of data exchanged with the module, one JSON object
+// per line in a capture file. Text is kept readable in Data, anything else
+// is hex encoded in Hex.
+type CaptureRecord struct {
+	Time time.Time `json:"time"`
+	Dir  string    `json:"dir"`
+	Data string    `json:"data,omitempty"`
+	Hex  string    `json:"hex,omitempty"`
+}
+
+func newCaptureRecord(dir string, b []byte) CaptureRecord {
+	rec := CaptureRecord{Time: time.Now().UTC(), Dir: dir}
+	if utf8.Valid(b) {
+		rec.Data = string(b)
+	} else {
+		rec.Hex = hex.EncodeToString(b)
+	}
+	return rec
+}
+
+// Bytes returns the data of the record.
+func (r CaptureRecord) Bytes() ([]byte, error) {
+	if r.Hex != "" {
+		return hex.DecodeString(r.Hex)
+	}
+	return []byte(r.Data), nil
+}
+
+// Recorder port writing every chunk read or written to a capture. The
+// LoRaWAN keys are redacted, from the set_config commands and the
+// get_config replies, so a capture can be shared. The reply of a
+// get_config of a key is recorded once its line is complete.
+type Recorder struct {
+	port io.ReadWriteCloser
+
+	mu  sync.Mutex
+	enc *json.Encoder
+	// secret get_config command of a key, its reply is held back until
+	// the end of its line.
+	secret string
+	reply  []byte
+}
+
+// NewRecorder records the traffic of port to w as JSON lines.
+func NewRecorder(port io.ReadWriteCloser, w io.Writer) *Recorder {
+	return &Recorder{
+		port: port,
+		enc:  json.NewEncoder(w),
+	}
+}
+
+func (r *Recorder) Read(p []byte) (int, error) {
+	n, err := r.port.Read(p)
+	if n > 0 {
+		r.record(CaptureRx, p[:n])
+	}
+	return n, err
+}
+
+func (r *Recorder) Write(p []byte) (int, error) {
+	n, err := r.port.Write(p)
+	if n > 0 {
+		r.record(CaptureTx, p[:n])
+	}
+	return n, err
+}
+
+// Close closes the port, the capture writer is left open.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	r.flush()
+	r.mu.Unlock()
+	return r.port.Close()
+}
+
+func (r *Recorder) record(dir string, b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case dir == CaptureTx:
+		r.flush()
+		if cmd, ok := strings.CutPrefix(string(b), "at+"); ok {
+			b = []byte("at+" + redact(cmd))
+			key, ok := strings.CutPrefix(strings.TrimSuffix(cmd, CrLf), "get_config=")
+			if ok && IsSecretConfigKey(key) {
+				r.secret = "get_config=" + key
+			}
+		}
+	case r.secret != "":
+		r.reply = append(r.reply, b...)
+		if bytes.IndexByte(r.reply, '\n') >= 0 {
+			r.flush()
+		}
+		return
+	}
+
+	// a failing capture must not break the port
+	_ = r.enc.Encode(newCaptureRecord(dir, b))
+}
+
+// flush records the reply held back, its first line redacted, even
+// without its end when the next command is written. r.mu must be held.
+func (r *Recorder) flush() {
+	if r.secret != "" && len(r.reply) > 0 {
+		line, rest := r.reply, []byte(nil)
+		if i := bytes.IndexByte(r.reply, '\n'); i >= 0 {
+			line, rest = r.reply[:i+1], r.reply[i+1:]
+		}
+		resp := strings.TrimRight(string(line), CrLf)
+		b := append([]byte(redactReply(r.secret, resp)+string(line[len(resp):])), rest...)
+		_ = r.enc.Encode(newCaptureRecord(CaptureRx, b))
+	}
+	r.secret, r.reply = "", nil
+}
+
+// Replay port playing a capture back: every write must match the next
+// data written in the capture, then the data the module answered is
+// returned by the reads. Reads return io.EOF, like a serial read timeout,
+// when the module has nothing more to say until the next write.
+type Replay struct {
+	mu      sync.Mutex
+	records []CaptureRecord
+	pending []byte
+	closed  bool
+}
+
+// NewReplay reads the capture from r.
+func NewReplay(r io.Reader) (*Replay, error) {
+	var records []CaptureRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var rec CaptureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("invalid capture record at line %d: %v", line, err)
+		}
+		if rec.Dir != CaptureTx && rec.Dir != CaptureRx {
+			return nil, fmt.Errorf("invalid capture direction at line %d: %q", line, rec.Dir)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Replay{records: records}, nil
+}
+
+// OpenReplay reads the capture file name.
+func OpenReplay(name string) (*Replay, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewReplay(f)
+}
+
+func (r *Replay) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, errPortClosed
+	}
+
+	for len(r.pending) == 0 {
+		if len(r.records) == 0 || r.records[0].Dir != CaptureRx {
+			return 0, io.EOF
+		}
+		b, err := r.records[0].Bytes()
+		if err != nil {
+			return 0, err
+		}
+		r.pending = b
+		r.records = r.records[1:]
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *Replay) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, errPortClosed
+	}
+
+	// the module output not read before this write is discarded
+	r.pending = nil
+	for len(r.records) > 0 && r.records[0].Dir == CaptureRx {
+		r.records = r.records[1:]
+	}
+
+	// the keys were redacted when recorded, and aren't reported
+	want := p
+	if cmd, ok := strings.CutPrefix(string(p), "at+"); ok {
+		want = []byte("at+" + redact(cmd))
+	}
+	written := want
+	for len(want) > 0 {
+		if len(r.records) == 0 {
+			return 0, fmt.Errorf("replay: unexpected write %q past the end of the capture", written)
+		}
+		b, err := r.records[0].Bytes()
+		if err != nil {
+			return 0, err
+		}
+		if len(b) > len(want) || string(b) != string(want[:len(b)]) {
+			return 0, fmt.Errorf("replay: unexpected write %q, capture has %q", written, b)
+		}
+		want = want[len(b):]
+		r.records = r.records[1:]
+	}
+	return len(p), nil
+}
+
+// Close closes the replay, the remaining records are discarded.
+func (r *Replay) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	return nil
+}
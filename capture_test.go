@@ -0,0 +1,182 @@
+package rak811
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestRecorder(t *testing.T) {
+	var capture bytes.Buffer
+	lora, _ := newLora(NewRecorder(simulator.New().Port(), &capture))
+
+	if _, err := lora.Version(); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	lora.Close()
+
+	var records []CaptureRecord
+	dec := json.NewDecoder(&capture)
+	for dec.More() {
+		var rec CaptureRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		records = append(records, rec)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Dir != CaptureTx || records[0].Data != "at+version\r\n" {
+		t.Errorf("got %+v", records[0])
+	}
+	if records[1].Dir != CaptureRx || records[1].Data != "OK2.0.3.0\r\n" || records[1].Time.IsZero() {
+		t.Errorf("got %+v", records[1])
+	}
+}
+
+func TestRecorder_RedactsKeys(t *testing.T) {
+	const key = "a6b08140dae1d795ebfa5a6dee1f4dbd"
+	var capture bytes.Buffer
+	recorded, _ := newLora(NewRecorder(simulator.New().Port(), &capture))
+
+	if _, err := recorded.SetConfig("app_key:" + key + "&dev_eui:0102030405060708"); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if resp, err := recorded.GetConfig("app_key"); err != nil || resp != "OK"+key {
+		t.Fatalf("got %q, %v", resp, err)
+	}
+	if resp, err := recorded.GetConfig("dev_eui"); err != nil || resp != "OK0102030405060708" {
+		t.Fatalf("got %q, %v", resp, err)
+	}
+	if strings.Contains(capture.String(), key) {
+		t.Fatalf("key recorded in %s", capture.String())
+	}
+
+	// the redacted capture replays the same commands
+	replay, err := NewReplay(&capture)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	lora, _ := newLora(replay)
+	if _, err := lora.SetConfig("app_key:" + key + "&dev_eui:0102030405060708"); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if resp, err := lora.GetConfig("app_key"); err != nil || resp != "OK<redacted>" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+	if resp, err := lora.GetConfig("dev_eui"); err != nil || resp != "OK0102030405060708" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+}
+
+func TestRecorder_SecretWithoutNewline(t *testing.T) {
+	var capture bytes.Buffer
+	r := NewRecorder(nil, &capture)
+
+	// the reply held back is recorded before the next command
+	r.record(CaptureTx, []byte("at+get_config=app_key\r\n"))
+	r.record(CaptureRx, []byte("OKa6b08140dae1d795"))
+	r.record(CaptureTx, []byte("at+version\r\n"))
+
+	var data []string
+	dec := json.NewDecoder(&capture)
+	for dec.More() {
+		var rec CaptureRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		data = append(data, rec.Dir+":"+rec.Data)
+	}
+	want := []string{"tx:at+get_config=app_key\r\n", "rx:OK<redacted>", "tx:at+version\r\n"}
+	if strings.Join(data, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
+
+func TestRecorder_Binary(t *testing.T) {
+	rec := newCaptureRecord(CaptureRx, []byte{0xf0, 0x0f})
+	if rec.Data != "" || rec.Hex != "f00f" {
+		t.Errorf("got %+v", rec)
+	}
+	b, err := rec.Bytes()
+	if err != nil || !bytes.Equal(b, []byte{0xf0, 0x0f}) {
+		t.Errorf("got %v, %v", b, err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	module := simulator.New()
+	var capture bytes.Buffer
+	recorded, _ := newLora(NewRecorder(module.Port(), &capture))
+
+	want := make([]string, 0, 3)
+	for _, cmd := range []func(l *Lora) (string, error){
+		(*Lora).Version,
+		(*Lora).JoinOTAA,
+		func(l *Lora) (string, error) { return l.Send("0,2,CAFE") },
+	} {
+		resp, err := cmd(recorded)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		want = append(want, resp)
+	}
+
+	replay, err := NewReplay(&capture)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	lora, _ := newLora(replay)
+
+	actual := make([]string, 0, 3)
+	for _, cmd := range []func() (string, error){
+		lora.Version,
+		lora.JoinOTAA,
+		func() (string, error) { return lora.Send("0,2,CAFE") },
+	} {
+		resp, err := cmd()
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		actual = append(actual, resp)
+	}
+
+	if strings.Join(actual, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", actual, want)
+	}
+
+	if _, err := lora.Version(); err == nil {
+		t.Error("want error past the end of the capture")
+	}
+}
+
+func TestReplay_UnexpectedWrite(t *testing.T) {
+	replay, err := NewReplay(strings.NewReader(`{"dir":"tx","data":"at+version\r\n"}` + "\n"))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := replay.Write(createCmd("band")); err == nil {
+		t.Error("want error on unexpected write")
+	}
+}
+
+func TestNew_Replay(t *testing.T) {
+	// the join result arrives in the same chunk as OK
+	lora, err := New(&Config{Name: "replay://testdata/join_otaa.jsonl"})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer lora.Close()
+
+	if resp, err := lora.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Fatalf("got %q, %v", resp, err)
+	}
+	if resp, err := lora.JoinOTAA(); err != nil || resp != JoinSuccess {
+		t.Errorf("got %q, %v, want %q", resp, err, JoinSuccess)
+	}
+}
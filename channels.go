@@ -0,0 +1,181 @@
+package rak811
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maskChannels channels covered by a word of the channel mask.
+	maskChannels = 16
+
+	// subBandChannels 125 kHz channels of a US915/AU915 sub-band.
+	subBandChannels = 8
+	// SubBands number of sub-bands of the US915 and AU915 regions.
+	SubBands = 8
+)
+
+// ChannelMask enabled uplink channels of the US915 and AU915 regions, bit
+// n%16 of word n/16 for channel n, as set with set_config=ch_mask.
+type ChannelMask [5]uint16
+
+// SubBandMask returns the mask of the sub-band n, 1 to 8: its eight
+// 125 kHz channels and its 500 kHz channel. TTN uses the sub-band 2.
+func SubBandMask(n int) (ChannelMask, error) {
+	var m ChannelMask
+	if n < 1 || n > SubBands {
+		return m, fmt.Errorf("invalid sub-band: %d", n)
+	}
+	for ch := (n - 1) * subBandChannels; ch < n*subBandChannels; ch++ {
+		m.Set(ch, true)
+	}
+	m.Set(64+n-1, true)
+	return m, nil
+}
+
+// Enabled reports whether the channel is enabled.
+func (m ChannelMask) Enabled(ch int) bool {
+	if ch < 0 || ch >= len(m)*maskChannels {
+		return false
+	}
+	return m[ch/maskChannels]&(1<<(ch%maskChannels)) != 0
+}
+
+// Set enables or disables the channel, out of range channels are ignored.
+func (m *ChannelMask) Set(ch int, on bool) {
+	if ch < 0 || ch >= len(m)*maskChannels {
+		return
+	}
+	if on {
+		m[ch/maskChannels] |= 1 << (ch % maskChannels)
+	} else {
+		m[ch/maskChannels] &^= 1 << (ch % maskChannels)
+	}
+}
+
+// Channels returns the enabled channels in ascending order.
+func (m ChannelMask) Channels() []int {
+	var chs []int
+	for ch := 0; ch < len(m)*maskChannels; ch++ {
+		if m.Enabled(ch) {
+			chs = append(chs, ch)
+		}
+	}
+	return chs
+}
+
+// Validate checks the mask enables at least one channel and only the
+// channels of the region, which must be US915 or AU915.
+func (m ChannelMask) Validate(region Region) error {
+	if region != US915 && region != AU915 {
+		return fmt.Errorf("channel masks are not supported in region %s", region)
+	}
+	chs := m.Channels()
+	if len(chs) == 0 {
+		return fmt.Errorf("channel mask enables no channel")
+	}
+	if n := len(region.Plan().Channels); chs[len(chs)-1] >= n {
+		return fmt.Errorf("channel %d is not in region %s", chs[len(chs)-1], region)
+	}
+	return nil
+}
+
+// ChannelStatus uplink channel of the module, the frequency and data
+// rates are only known for the enabled channels.
+type ChannelStatus struct {
+	Index   int
+	Enabled bool
+	Channel
+}
+
+// ParseChannelList parses the get_config=ch_list response, with or
+// without the OK prefix.
+// Format: <index>,on,<frequency>,<min dr>,<max dr>;<index>,off;...
+func ParseChannelList(resp string) ([]ChannelStatus, error) {
+	list := strings.TrimSpace(strings.TrimPrefix(resp, OK))
+	if list == "" {
+		return nil, nil
+	}
+
+	var chs []ChannelStatus
+	for _, entry := range strings.Split(list, ";") {
+		fields := strings.Split(entry, ",")
+		values := make([]int, len(fields))
+		for i, f := range fields {
+			if i == 1 {
+				continue
+			}
+			v, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return nil, fmt.Errorf("invalid channel list %q: %v", resp, err)
+			}
+			values[i] = v
+		}
+
+		switch {
+		case len(fields) == 5 && fields[1] == "on":
+			chs = append(chs, ChannelStatus{
+				Index:   values[0],
+				Enabled: true,
+				Channel: Channel{Frequency: values[2], MinDR: values[3], MaxDR: values[4]},
+			})
+		case len(fields) == 2 && fields[1] == "off":
+			chs = append(chs, ChannelStatus{Index: values[0]})
+		default:
+			return nil, fmt.Errorf("invalid channel: %q", entry)
+		}
+	}
+	return chs, nil
+}
+
+// GetChannels returns the uplink channels of the module.
+func (l *Lora) GetChannels() ([]ChannelStatus, error) {
+	resp, err := l.GetConfig("ch_list")
+	if err != nil {
+		return nil, err
+	}
+	if err := isError(resp); err != nil {
+		return nil, err
+	}
+	return ParseChannelList(resp)
+}
+
+// SetChannelMask enables the channels of the mask and disables the
+// others, with a single set_config command setting every word of the
+// mask. The module may have applied some of the words when it fails,
+// GetChannels reads back the channels enabled. The region of the module
+// must be US915 or AU915.
+func (l *Lora) SetChannelMask(mask ChannelMask) (string, error) {
+	r, err := l.region()
+	if err != nil {
+		return "", err
+	}
+	if err := mask.Validate(r); err != nil {
+		return "", err
+	}
+
+	words := make([]string, len(mask))
+	for i, word := range mask {
+		words[i] = fmt.Sprintf("ch_mask:%d,%04X", i, word)
+	}
+	resp, err := l.SetConfig(strings.Join(words, "&"))
+	if err != nil {
+		return resp, err
+	}
+	if err := isError(resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// SetSubBand enables the sub-band n, 1 to 8, and disables the other
+// channels, so the module only joins and sends on the channels of the
+// gateways.
+func (l *Lora) SetSubBand(n int) (string, error) {
+	mask, err := SubBandMask(n)
+	if err != nil {
+		return "", err
+	}
+	return l.SetChannelMask(mask)
+}
@@ -0,0 +1,122 @@
+package rak811
+
+import (
+	"testing"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestSubBandMask(t *testing.T) {
+	m, err := SubBandMask(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (ChannelMask{0xFF00, 0, 0, 0, 0x0002}) {
+		t.Errorf("got %04X", m)
+	}
+	want := []int{8, 9, 10, 11, 12, 13, 14, 15, 65}
+	if chs := m.Channels(); !equalInts(chs, want) {
+		t.Errorf("got %v, want %v", chs, want)
+	}
+
+	for _, n := range []int{0, 9} {
+		if _, err := SubBandMask(n); err == nil {
+			t.Errorf("want error for sub-band %d", n)
+		}
+	}
+}
+
+func TestChannelMask_Validate(t *testing.T) {
+	m, _ := SubBandMask(8)
+	if err := m.Validate(US915); err != nil {
+		t.Error(err)
+	}
+	if err := m.Validate(EU868); err == nil {
+		t.Error("want error for EU868")
+	}
+	if err := (ChannelMask{}).Validate(AU915); err == nil {
+		t.Error("want error for an empty mask")
+	}
+	m.Set(72, true)
+	if err := m.Validate(US915); err == nil {
+		t.Error("want error for channel 72")
+	}
+	m.Set(72, false)
+	m.Set(71, false)
+	if m.Enabled(71) || !m.Enabled(63) || m.Enabled(-1) {
+		t.Errorf("got %04X", m)
+	}
+}
+
+func TestParseChannelList(t *testing.T) {
+	chs, err := ParseChannelList("OK0,on,902300000,0,3;1,off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ChannelStatus{
+		{Index: 0, Enabled: true, Channel: Channel{Frequency: 902300000, MinDR: 0, MaxDR: 3}},
+		{Index: 1},
+	}
+	if len(chs) != len(want) || chs[0] != want[0] || chs[1] != want[1] {
+		t.Errorf("got %+v, want %+v", chs, want)
+	}
+
+	for _, in := range []string{"OK0,on", "OK0,maybe", "OKx,off", "OK0,on,1,2"} {
+		if _, err := ParseChannelList(in); err == nil {
+			t.Errorf("want error for %q", in)
+		}
+	}
+}
+
+func TestLora_SetSubBand(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	if _, err := l.SetSubBand(2); err == nil {
+		t.Fatal("want error in EU868")
+	}
+	if _, err := l.SetBand(US915); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.SetSubBand(2); err != nil {
+		t.Fatal(err)
+	}
+
+	chs, err := l.GetChannels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var enabled []int
+	for _, ch := range chs {
+		if ch.Enabled {
+			enabled = append(enabled, ch.Index)
+		}
+	}
+	if !equalInts(enabled, []int{8, 9, 10, 11, 12, 13, 14, 15, 65}) {
+		t.Errorf("got %v", enabled)
+	}
+	if chs[8].Frequency != 903900000 || chs[65].Frequency != 904600000 || chs[65].MinDR != 4 {
+		t.Errorf("got %+v, %+v", chs[8], chs[65])
+	}
+
+	want := []string{
+		"band", "band=US915",
+		"set_config=ch_mask:0,FF00&ch_mask:1,0000&ch_mask:2,0000&ch_mask:3,0000&ch_mask:4,0002",
+		"get_config=ch_list",
+	}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,321 @@
+package rak811
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrListening the background reader of class B/C owns the port, LoraP2P
+// is not available.
+var ErrListening = errors.New("background reader is running, p2p needs class A")
+
+// Class LoRaWAN device class, as understood by set_config=class.
+type Class int
+
+const (
+	// ClassA downlinks are only received after an uplink.
+	ClassA Class = 0
+	// ClassB downlinks are also received in the scheduled ping slots.
+	ClassB Class = 1
+	// ClassC downlinks are received whenever the device isn't sending.
+	ClassC Class = 2
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassA:
+		return "A"
+	case ClassB:
+		return "B"
+	case ClassC:
+		return "C"
+	}
+	return "unknown"
+}
+
+// DownlinkHandlers callbacks of the downlinks delivered by the background
+// reader, called one at a time from their own goroutine.
+type DownlinkHandlers struct {
+	// Unicast called with the downlinks sent to the device.
+	Unicast func(Frame)
+	// Multicast called with the downlinks of the multicast groups.
+	Multicast func(Frame)
+	// MulticastPorts FPorts of the multicast groups. The module doesn't
+	// report the address a downlink was sent to, so the groups are told
+	// apart by port.
+	MulticastPorts []uint8
+}
+
+// multicast reports whether the frame belongs to a multicast group.
+func (h DownlinkHandlers) multicast(f Frame) bool {
+	for _, p := range h.MulticastPorts {
+		if p == f.Port {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClass returns the LoRaWAN class of the module.
+func (l *Lora) GetClass() (Class, error) {
+	resp, err := l.GetConfig("class")
+	if err != nil {
+		return ClassA, err
+	}
+	if err := isError(resp); err != nil {
+		return ClassA, err
+	}
+	c, err := strconv.Atoi(strings.TrimPrefix(resp, OK))
+	if err != nil || c < int(ClassA) || c > int(ClassC) {
+		return ClassA, fmt.Errorf("invalid class: %q", resp)
+	}
+	return Class(c), nil
+}
+
+// SetClass sets the LoRaWAN class of the module. Switching to class B or
+// C starts the background reader, which delivers the downlinks received
+// at any time to the DownlinkHandlers. The reader then owns the port
+// until the Lora is closed, even if switched back to class A.
+//
+// The module reports the downlink received in the receive windows of an
+// uplink in place of its tx event, and the other downlinks with the same
+// at+recv=0 event. So a downlink received between the OK of a Send and
+// its tx event is returned by the Send as well as delivered to the
+// handlers, and the tx event following it is dropped.
+func (l *Lora) SetClass(class Class) (string, error) {
+	if class < ClassA || class > ClassC {
+		return "", fmt.Errorf("invalid class: %d", class)
+	}
+
+	resp, err := l.SetConfig(fmt.Sprintf("class:%d", class))
+	if err != nil {
+		return resp, err
+	}
+	if err := isError(resp); err != nil {
+		return resp, err
+	}
+	if class != ClassA {
+		l.listen()
+	}
+	return resp, nil
+}
+
+// HandleDownlinks sets the callbacks of the downlinks delivered by the
+// background reader. They aren't called with a Config.Router, which
+// receives the downlinks instead.
+func (l *Lora) HandleDownlinks(h DownlinkHandlers) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers = h
+}
+
+// reply line or error read by the background reader.
+type reply struct {
+	line string
+	err  error
+}
+
+// reader background reader of the port. Replies are passed to the
+// command in progress, downlinks are dispatched to the handlers and
+// the other unsolicited lines are dropped.
+type reader struct {
+	lora *Lora
+
+	mu       sync.Mutex
+	awaiting bool
+	// event the command in progress replies with an event after OK, ok
+	// its OK was delivered.
+	event   bool
+	ok      bool
+	replies chan reply
+	frames  chan Frame
+}
+
+// repliesWithEvent reports whether the command replies with an at+recv
+// event after its OK.
+func repliesWithEvent(cmd string) bool {
+	return commandName(cmd) == "send" || cmd == "join=otaa"
+}
+
+// listen starts the background reader, once.
+func (l *Lora) listen() {
+	l.mu.Lock()
+	if l.reader != nil {
+		l.mu.Unlock()
+		return
+	}
+	r := &reader{
+		lora:    l,
+		replies: make(chan reply, frameBacklog),
+		frames:  make(chan Frame, frameBacklog),
+	}
+	l.reader = r
+	lines := l.lines
+	l.mu.Unlock()
+
+	go r.run(lines)
+	go r.dispatch()
+}
+
+// backgroundReader returns the background reader, nil unless listening.
+func (l *Lora) backgroundReader() *reader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reader
+}
+
+// begin starts waiting for the replies of a command, event whether it
+// replies with an event after OK. Stale replies of a previous command
+// are discarded.
+func (r *reader) begin(event bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.drain()
+	r.awaiting = true
+	r.event = event
+	r.ok = false
+}
+
+// drain discards the replies left, r.mu must be held.
+func (r *reader) drain() {
+	for {
+		select {
+		case <-r.replies:
+		default:
+			return
+		}
+	}
+}
+
+// end stops waiting for replies.
+func (r *reader) end() {
+	r.mu.Lock()
+	r.awaiting = false
+	r.mu.Unlock()
+}
+
+// next returns the next reply of the command in progress.
+func (r *reader) next() (string, error) {
+	select {
+	case rep := <-r.replies:
+		return rep.line, rep.err
+	case <-r.lora.closing:
+		return "", ErrDisconnected
+	}
+}
+
+// deliver passes the reply to the command in progress, if any. Events
+// are only passed once the OK of a command replying with an event was,
+// otherwise they are unsolicited. The first event after the OK is the
+// reply, even a downlink received outside of the receive windows, which
+// can't be told apart.
+func (r *reader) deliver(rep reply) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.awaiting {
+		return false
+	}
+	if WhichEventResponse(rep.line) != nil {
+		if !r.event || !r.ok {
+			return false
+		}
+	} else if isOk(rep.line) {
+		r.ok = true
+	}
+	select {
+	case r.replies <- rep:
+	default:
+		r.lora.log.Warn("reply dropped", "reply", rep.line)
+	}
+	return true
+}
+
+// run reads the lines of a port until it fails, the reader of the next
+// port is started once reconnected.
+func (r *reader) run(lines *lineScanner) {
+	l := r.lora
+	for {
+		line, err := lines.next()
+		if err != nil {
+			// serial timeout has triggered
+			if err == io.EOF {
+				resp := strings.TrimSpace(lines.pending())
+				if isOk(resp) {
+					lines.reset()
+					r.deliver(reply{line: resp})
+				} else if err := isError(resp); err != nil {
+					lines.reset()
+					r.deliver(reply{err: err})
+				}
+				select {
+				case <-l.closing:
+					return
+				default:
+				}
+				continue
+			}
+			if err == errLineTooLong {
+				l.log.Warn("dropped over-long line")
+				continue
+			}
+
+			err = &portError{fmt.Errorf("failed read: %v", err)}
+			r.deliver(reply{err: err})
+			l.lost(err)
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		l.logEvent(line)
+		l.observeEvent(line)
+		l.route(line)
+
+		if evt := WhichEventResponse(line); l.router == nil && evt != nil && evt.Code() == StatusRecvData {
+			if frame, err := ParseFrame(line); err != nil {
+				l.log.Warn("invalid downlink", "event", line, "error", err)
+			} else {
+				select {
+				case r.frames <- *frame:
+				default:
+					l.log.Warn("downlink dropped", "port", frame.Port)
+				}
+			}
+		}
+		if !r.deliver(reply{line: line}) {
+			l.log.Debug("unsolicited line", "line", line)
+		}
+	}
+}
+
+// dispatch calls the handlers with the downlinks until the Lora is
+// closed.
+func (r *reader) dispatch() {
+	l := r.lora
+	for {
+		select {
+		case f := <-r.frames:
+			l.mu.Lock()
+			h := l.handlers
+			l.mu.Unlock()
+
+			fn := h.Unicast
+			if h.multicast(f) {
+				fn = h.Multicast
+			}
+			if fn != nil {
+				fn(f)
+			}
+		case <-l.closing:
+			return
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package rak811
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+	"github.com/tarm/serial"
+)
+
+func nextFrame(t *testing.T, frames <-chan Frame) Frame {
+	t.Helper()
+
+	select {
+	case f := <-frames:
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatal("no downlink")
+	}
+	return Frame{}
+}
+
+func TestLora_SetClass(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	unicast := make(chan Frame, 4)
+	multicast := make(chan Frame, 4)
+	l.HandleDownlinks(DownlinkHandlers{
+		Unicast:        func(f Frame) { unicast <- f },
+		Multicast:      func(f Frame) { multicast <- f },
+		MulticastPorts: []uint8{200},
+	})
+
+	if _, err := l.SetClass(Class(3)); err == nil {
+		t.Fatal("want error for an invalid class")
+	}
+	if _, err := l.SetClass(ClassC); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := l.GetClass(); err != nil || c != ClassC {
+		t.Errorf("got %v, %v", c, err)
+	}
+
+	module.Emit("at+recv=0,5,-80,3,2:BEEF")
+	if f := nextFrame(t, unicast); f.Port != 5 || f.RSSI != -80 || string(f.Payload) != "\xbe\xef" {
+		t.Errorf("got %+v", f)
+	}
+	module.Emit("at+recv=0,200,-70,4,1:01")
+	if f := nextFrame(t, multicast); f.Port != 200 {
+		t.Errorf("got %+v", f)
+	}
+
+	// unsolicited lines aren't taken for replies
+	module.Emit("at+recv=8,0,0")
+	time.Sleep(10 * time.Millisecond)
+	if resp, err := l.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+
+	// the downlinks of an uplink are returned and delivered
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+	module.QueueDownlink(simulator.Downlink{Port: 7, RSSI: -60, SNR: 5, Payload: []byte{1}})
+	if resp, err := l.Send("0,1,01"); err != nil || resp != "at+recv=0,7,-60,5,1:01" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+	if f := nextFrame(t, unicast); f.Port != 7 {
+		t.Errorf("got %+v", f)
+	}
+
+	if _, err := NewP2P(l, DefaultRFConfig); err != ErrListening {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestLora_ClassCReconnect(t *testing.T) {
+	module := simulator.New()
+
+	var mu sync.Mutex
+	var ports []*flakyPort
+	open := openSerial
+	defer func() { openSerial = open }()
+	openSerial = func(c *serial.Config) (io.ReadWriteCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		p := &flakyPort{ReadWriteCloser: module.Port()}
+		ports = append(ports, p)
+		return p, nil
+	}
+
+	connected := make(chan struct{}, 1)
+	lora, err := New(&Config{
+		Name:             "/dev/ttyUSB0",
+		ReconnectBackoff: 10 * time.Millisecond,
+		OnStateChange: func(state ConnState, err error) {
+			if state == StateConnected {
+				connected <- struct{}{}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lora.Close()
+
+	frames := make(chan Frame, 4)
+	lora.HandleDownlinks(DownlinkHandlers{Unicast: func(f Frame) { frames <- f }})
+	if _, err := lora.SetClass(ClassC); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	ports[0].Break()
+	mu.Unlock()
+	if _, err := lora.Version(); err == nil {
+		t.Fatal("want error on broken port")
+	}
+
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reconnected")
+	}
+	if resp, err := lora.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Errorf("got %q, %v after reconnecting", resp, err)
+	}
+	module.Emit("at+recv=0,5,-80,3,1:01")
+	if f := nextFrame(t, frames); f.Port != 5 {
+		t.Errorf("got %+v", f)
+	}
+}
+
+func TestLora_ClassCDownlinkDuringCommand(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	frames := make(chan Frame, 4)
+	l.HandleDownlinks(DownlinkHandlers{Unicast: func(f Frame) { frames <- f }})
+	if _, err := l.SetClass(ClassC); err != nil {
+		t.Fatal(err)
+	}
+
+	module.HandleFunc("signal", func(args string) []string {
+		return []string{"at+recv=0,5,-80,3,1:01", "at+recv=8,0,0", "OK-40,7"}
+	})
+	if resp, err := l.Signal(); err != nil || resp != "OK-40,7" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+	if f := nextFrame(t, frames); f.Port != 5 {
+		t.Errorf("got %+v", f)
+	}
+
+	// the event of an uplink still follows its OK
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+	module.HandleFunc("send", func(args string) []string {
+		return []string{"at+recv=0,6,-80,3,1:02", "OK", "at+recv=2,0,0"}
+	})
+	if resp, err := l.Send("0,1,00"); err != nil || resp != "at+recv=2,0,0" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+	if f := nextFrame(t, frames); f.Port != 6 {
+		t.Errorf("got %+v", f)
+	}
+
+	// a downlink between the OK and the tx event is the reply too, the tx
+	// event is dropped without shifting the next replies
+	module.HandleFunc("send", func(args string) []string {
+		return []string{"OK", "at+recv=0,7,-80,3,1:03", "at+recv=2,0,0"}
+	})
+	if resp, err := l.Send("0,1,00"); err != nil || resp != "at+recv=0,7,-80,3,1:03" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+	if f := nextFrame(t, frames); f.Port != 7 {
+		t.Errorf("got %+v", f)
+	}
+	if resp, err := l.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Errorf("got %q, %v", resp, err)
+	}
+}
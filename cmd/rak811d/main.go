@@ -0,0 +1,100 @@
+// Command rak811d owns the port of a RAK811 module and serves a REST API
+// for applications that can't link the Go library:
+//
+//	GET  /version          module firmware version
+//	GET  /config?key=...   LoRaWAN configuration, dev_eui, app_eui, dev_addr and class by default
+//	PUT  /config           sets the configuration from a JSON object
+//	POST /join             joins the network, {"mode": "otaa"} or "abp"
+//	POST /uplinks          sends {"port": 1, "confirmed": false, "payload": "<base64>"}
+//	GET  /downlinks        downlinks streamed as server-sent events
+//	GET  /signal           RSSI and SNR of the last packet received
+//	GET  /radio/status     radio statistics
+//
+// Errors are returned as {"error": "...", "code": -5}, with the module
+// error code if any.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/calvernaz/rak811"
+)
+
+func main() {
+	var (
+		device  = flag.String("device", "/dev/ttyAMA0", "serial device, tcp://host:port or rfc2217://host:port")
+		baud    = flag.Int("baud", 115200, "serial baud rate")
+		addr    = flag.String("listen", ":8080", "HTTP listen address")
+		timeout = flag.Duration("timeout", 60*time.Second, "serial read timeout")
+		debug   = flag.Bool("debug", false, "log the AT commands")
+	)
+	flag.Parse()
+
+	level := slog.LevelInfo
+	if *debug {
+		level = slog.LevelDebug
+	}
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	if err := run(log, *addr, &rak811.Config{
+		Name:    *device,
+		Baud:    *baud,
+		Timeout: *timeout,
+		Logger:  log,
+	}); err != nil {
+		log.Error("rak811d failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(log *slog.Logger, addr string, conf *rak811.Config) error {
+	lora, err := rak811.New(conf)
+	if err != nil {
+		return err
+	}
+	defer lora.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// cancelled on shutdown, so the event streams end
+	base, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newServer(lora, log),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return base },
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Info("listening", "addr", addr, "device", conf.Name)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down")
+	cancel()
+	shutdown, done := context.WithTimeout(context.Background(), 10*time.Second)
+	defer done()
+	if err := srv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
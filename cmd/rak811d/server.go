@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvernaz/rak811"
+)
+
+// defaultConfigKeys keys returned by GET /config without a key parameter.
+var defaultConfigKeys = []string{"dev_eui", "app_eui", "dev_addr", "class"}
+
+// keepAlive interval of the comments sent on idle event streams.
+var keepAlive = 15 * time.Second
+
+// uplinkRequest body of POST /uplinks, the payload is base64 encoded.
+type uplinkRequest struct {
+	Port      uint8  `json:"port"`
+	Confirmed bool   `json:"confirmed"`
+	Payload   []byte `json:"payload"`
+}
+
+// uplinkResponse result of an uplink, with the downlink received in
+// its receive windows if any.
+type uplinkResponse struct {
+	Status   string    `json:"status"`
+	Downlink *downlink `json:"downlink,omitempty"`
+}
+
+// downlink data received from the network, the payload is base64 encoded.
+type downlink struct {
+	Port    uint8  `json:"port"`
+	RSSI    int    `json:"rssi"`
+	SNR     int    `json:"snr"`
+	Payload []byte `json:"payload"`
+}
+
+type joinRequest struct {
+	Mode string `json:"mode"`
+}
+
+type joinResponse struct {
+	Result string `json:"result"`
+}
+
+type signalResponse struct {
+	RSSI int `json:"rssi"`
+	SNR  int `json:"snr"`
+}
+
+type radioStatusResponse struct {
+	TxSuccess int `json:"tx_success"`
+	TxError   int `json:"tx_error"`
+	RxSuccess int `json:"rx_success"`
+	RxTimeout int `json:"rx_timeout"`
+	RxError   int `json:"rx_error"`
+	RSSI      int `json:"rssi"`
+	SNR       int `json:"snr"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  int    `json:"code,omitempty"`
+}
+
+// server REST API of a module. The module answers one command at a time,
+// the requests are serialized.
+type server struct {
+	mu   sync.Mutex
+	lora *rak811.Lora
+
+	log       *slog.Logger
+	downlinks *broker
+	mux       *http.ServeMux
+}
+
+func newServer(lora *rak811.Lora, log *slog.Logger) *server {
+	s := &server{
+		lora:      lora,
+		log:       log,
+		downlinks: newBroker(),
+		mux:       http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/version", s.method(http.MethodGet, s.version))
+	s.mux.HandleFunc("/config", s.config)
+	s.mux.HandleFunc("/join", s.method(http.MethodPost, s.join))
+	s.mux.HandleFunc("/uplinks", s.method(http.MethodPost, s.uplink))
+	s.mux.HandleFunc("/downlinks", s.method(http.MethodGet, s.stream))
+	s.mux.HandleFunc("/signal", s.method(http.MethodGet, s.signal))
+	s.mux.HandleFunc("/radio/status", s.method(http.MethodGet, s.radioStatus))
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// method restricts a handler to a single method.
+func (s *server) method(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			s.fail(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+// do runs a module command, one at a time, traced as part of the
+// request of ctx. An ERROR-n reply is returned as the error.
+func (s *server) do(ctx context.Context, fn func(l *rak811.Lora) (string, error)) (string, error) {
+	var resp string
+	s.mu.Lock()
+	err := s.lora.WithContext(ctx, func(l *rak811.Lora) (err error) {
+		resp, err = fn(l)
+		return err
+	})
+	s.mu.Unlock()
+
+	if err == nil && rak811.WhichError(resp) != nil {
+		return "", errors.New(resp)
+	}
+	return resp, err
+}
+
+func (s *server) version(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.do(r.Context(), (*rak811.Lora).Version)
+	if err != nil {
+		s.moduleError(w, err)
+		return
+	}
+	s.reply(w, http.StatusOK, map[string]string{"version": strings.TrimPrefix(resp, rak811.OK)})
+}
+
+func (s *server) config(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getConfig(w, r)
+	case http.MethodPut:
+		s.putConfig(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		s.fail(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+func (s *server) getConfig(w http.ResponseWriter, r *http.Request) {
+	keys := r.URL.Query()["key"]
+	if len(keys) == 0 {
+		keys = defaultConfigKeys
+	}
+	for _, key := range keys {
+		if err := rak811.ValidateConfigKey(key); err != nil {
+			s.fail(w, http.StatusBadRequest, err)
+			return
+		}
+		if rak811.IsSecretConfigKey(key) {
+			s.fail(w, http.StatusForbidden, fmt.Errorf("%s can't be read", key))
+			return
+		}
+	}
+
+	config := make(map[string]string, len(keys))
+	for _, key := range keys {
+		resp, err := s.do(r.Context(), func(l *rak811.Lora) (string, error) {
+			return l.GetConfig(key)
+		})
+		if err != nil {
+			s.moduleError(w, err)
+			return
+		}
+		config[key] = strings.TrimPrefix(resp, rak811.OK)
+	}
+	s.reply(w, http.StatusOK, config)
+}
+
+func (s *server) putConfig(w http.ResponseWriter, r *http.Request) {
+	var config map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("invalid config: %v", err))
+		return
+	}
+	if len(config) == 0 {
+		s.fail(w, http.StatusBadRequest, errors.New("empty config"))
+		return
+	}
+
+	keys := make([]string, 0, len(config))
+	for key, value := range config {
+		if err := rak811.ValidateConfigKey(key); err != nil {
+			s.fail(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := rak811.ValidateConfigValue(value); err != nil {
+			s.fail(w, http.StatusBadRequest, err)
+			return
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + ":" + config[key]
+	}
+	if _, err := s.do(r.Context(), func(l *rak811.Lora) (string, error) {
+		return l.SetConfig(strings.Join(pairs, "&"))
+	}); err != nil {
+		s.moduleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) join(w http.ResponseWriter, r *http.Request) {
+	req := joinRequest{Mode: "otaa"}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Errorf("invalid join request: %v", err))
+			return
+		}
+	}
+
+	var join func(l *rak811.Lora) (string, error)
+	switch req.Mode {
+	case "otaa":
+		join = (*rak811.Lora).JoinOTAA
+	case "abp":
+		join = (*rak811.Lora).JoinABP
+	default:
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("invalid join mode %q", req.Mode))
+		return
+	}
+
+	resp, err := s.do(r.Context(), join)
+	if err != nil {
+		s.moduleError(w, err)
+		return
+	}
+
+	switch {
+	case resp == rak811.JoinSuccess, req.Mode == "abp" && strings.HasPrefix(resp, rak811.OK):
+		s.reply(w, http.StatusOK, joinResponse{Result: "success"})
+	case resp == rak811.JoinFail:
+		s.reply(w, http.StatusOK, joinResponse{Result: "failed"})
+	case resp == rak811.JoinTimeout:
+		s.reply(w, http.StatusOK, joinResponse{Result: "timeout"})
+	default:
+		s.fail(w, http.StatusBadGateway, fmt.Errorf("unexpected join response %q", resp))
+	}
+}
+
+func (s *server) uplink(w http.ResponseWriter, r *http.Request) {
+	var req uplinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("invalid uplink: %v", err))
+		return
+	}
+	if req.Port < 1 || req.Port > 223 {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("invalid port %d", req.Port))
+		return
+	}
+	if len(req.Payload) == 0 {
+		s.fail(w, http.StatusBadRequest, rak811.ErrEmptyPayload)
+		return
+	}
+
+	confirmed := 0
+	if req.Confirmed {
+		confirmed = 1
+	}
+	resp, err := s.do(r.Context(), func(l *rak811.Lora) (string, error) {
+		return l.Send(fmt.Sprintf("%d,%d,%X", confirmed, req.Port, req.Payload))
+	})
+	if err != nil {
+		s.moduleError(w, err)
+		return
+	}
+
+	evt := rak811.WhichEventResponse(resp)
+	if evt == nil {
+		s.fail(w, http.StatusBadGateway, fmt.Errorf("unexpected response %q", resp))
+		return
+	}
+
+	res := uplinkResponse{Status: "unconfirmed"}
+	if req.Confirmed {
+		res.Status = "confirmed"
+	}
+	switch evt.Code() {
+	case rak811.StatusTxConfirmed, rak811.StatusTxUnconfirmed:
+	case rak811.StatusRecvData:
+		frame, err := rak811.ParseFrame(resp)
+		if err != nil {
+			s.fail(w, http.StatusBadGateway, err)
+			return
+		}
+		d := downlink{Port: frame.Port, RSSI: frame.RSSI, SNR: frame.SNR, Payload: frame.Payload}
+		res.Downlink = &d
+		s.downlinks.publish(d)
+	case rak811.StatusTxTimeout:
+		s.reply(w, http.StatusGatewayTimeout, errorResponse{Error: evt.Description()})
+		return
+	default:
+		s.fail(w, http.StatusBadGateway, errors.New(evt.Description()))
+		return
+	}
+	s.reply(w, http.StatusOK, res)
+}
+
+// stream sends the downlinks as server-sent events until the client goes
+// away.
+func (s *server) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.fail(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	ch, cancel := s.downlinks.subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case d := <-ch:
+			data, err := json.Marshal(d)
+			if err != nil {
+				s.log.Error("failed to encode downlink", "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: downlink\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (s *server) signal(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.do(r.Context(), (*rak811.Lora).Signal)
+	if err != nil {
+		s.moduleError(w, err)
+		return
+	}
+	sig, err := rak811.ParseSignal(resp)
+	if err != nil {
+		s.fail(w, http.StatusBadGateway, err)
+		return
+	}
+	s.reply(w, http.StatusOK, signalResponse{RSSI: sig.RSSI, SNR: sig.SNR})
+}
+
+func (s *server) radioStatus(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.do(r.Context(), (*rak811.Lora).GetRadioStatus)
+	if err != nil {
+		s.moduleError(w, err)
+		return
+	}
+	st, err := rak811.ParseRadioStatus(resp)
+	if err != nil {
+		s.fail(w, http.StatusBadGateway, err)
+		return
+	}
+	s.reply(w, http.StatusOK, radioStatusResponse{
+		TxSuccess: st.TxSuccess,
+		TxError:   st.TxError,
+		RxSuccess: st.RxSuccess,
+		RxTimeout: st.RxTimeout,
+		RxError:   st.RxError,
+		RSSI:      st.RSSI,
+		SNR:       st.SNR,
+	})
+}
+
+// moduleError replies with the error of a command: 503 while the module
+// is disconnected, 502 with the module error code otherwise.
+func (s *server) moduleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, rak811.ErrDisconnected) {
+		s.fail(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if code, ok := rak811.ErrorCode(err); ok {
+		desc := err.Error()
+		if lerr := rak811.WhichError(desc); lerr != nil && lerr.Error() != "" {
+			desc = lerr.Error()
+		}
+		s.reply(w, http.StatusBadGateway, errorResponse{Error: desc, Code: code})
+		return
+	}
+	s.fail(w, http.StatusBadGateway, err)
+}
+
+func (s *server) fail(w http.ResponseWriter, status int, err error) {
+	s.reply(w, status, errorResponse{Error: err.Error()})
+}
+
+func (s *server) reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Warn("failed to write response", "error", err)
+	}
+}
+
+// broker fans the downlinks out to the event streams.
+type broker struct {
+	mu   sync.Mutex
+	subs map[chan downlink]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{subs: make(map[chan downlink]struct{})}
+}
+
+// subscribe returns a channel receiving the downlinks until cancel is
+// called. Downlinks are dropped for subscribers too slow to keep up.
+func (b *broker) subscribe() (<-chan downlink, func()) {
+	ch := make(chan downlink, 16)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *broker) publish(d downlink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- d:
+		default:
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811"
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *simulator.Module) {
+	t.Helper()
+
+	module := simulator.New()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go module.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	lora, err := rak811.New(&rak811.Config{Name: "tcp://" + ln.Addr().String(), Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(lora.Close)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(newServer(lora, log))
+	t.Cleanup(srv.Close)
+	return srv, module
+}
+
+func do(t *testing.T, method, url, body string, v interface{}) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServer_Version(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	var v map[string]string
+	if status := do(t, http.MethodGet, srv.URL+"/version", "", &v); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if v["version"] != "2.0.3.0" {
+		t.Errorf("got %v", v)
+	}
+
+	if status := do(t, http.MethodPost, srv.URL+"/version", "", nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d", status)
+	}
+}
+
+func TestServer_Config(t *testing.T) {
+	srv, module := newTestServer(t)
+
+	body := `{"app_eui": "39d7119f920f7952", "app_key": "a6b08140dae1d795ebfa5a6dee1f4dbd"}`
+	if status := do(t, http.MethodPut, srv.URL+"/config", body, nil); status != http.StatusNoContent {
+		t.Fatalf("got status %d", status)
+	}
+	cmds := module.Commands()
+	if last := cmds[len(cmds)-1]; last != "set_config=app_eui:39d7119f920f7952&app_key:a6b08140dae1d795ebfa5a6dee1f4dbd" {
+		t.Errorf("got %q", last)
+	}
+
+	var config map[string]string
+	if status := do(t, http.MethodGet, srv.URL+"/config", "", &config); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if config["app_eui"] != "39d7119f920f7952" || config["dev_eui"] != "0102030405060708" || len(config) != 4 {
+		t.Errorf("got %v", config)
+	}
+
+	if status := do(t, http.MethodGet, srv.URL+"/config?key=app_key", "", nil); status != http.StatusForbidden {
+		t.Errorf("got status %d", status)
+	}
+
+	var e errorResponse
+	if status := do(t, http.MethodGet, srv.URL+"/config?key=nope", "", &e); status != http.StatusBadGateway || e.Code != rak811.CodeArgNotFind {
+		t.Errorf("got status %d, %+v", status, e)
+	}
+
+	// no command injection through the keys or values
+	n := len(module.Commands())
+	if status := do(t, http.MethodGet, srv.URL+"/config?key=dev_eui%0D%0Aat%2Bset_config=app_eui:00", "", nil); status != http.StatusBadRequest {
+		t.Errorf("got status %d", status)
+	}
+	for _, body := range []string{
+		`{"app_eui": "39d7119f920f7952\r\nat+set_config=dev_eui:00"}`,
+		`{"app_eui\r\n": "39d7119f920f7952"}`,
+		`{"app_eui": "39d7119f920f7952&dev_eui:00"}`,
+		`{"dev_eui:00&app_eui": "39d7119f920f7952"}`,
+	} {
+		if status := do(t, http.MethodPut, srv.URL+"/config", body, nil); status != http.StatusBadRequest {
+			t.Errorf("%s: got status %d", body, status)
+		}
+	}
+	if cmds := module.Commands(); len(cmds) != n {
+		t.Errorf("got commands %q", cmds[n:])
+	}
+}
+
+func TestServer_Join(t *testing.T) {
+	srv, module := newTestServer(t)
+
+	var join joinResponse
+	module.SetJoinResult("at+recv=4,0,0")
+	if status := do(t, http.MethodPost, srv.URL+"/join", "", &join); status != http.StatusOK || join.Result != "failed" {
+		t.Errorf("got status %d, %+v", status, join)
+	}
+
+	var e errorResponse
+	module.HandleFunc("join", func(args string) []string { return []string{"ERROR-4"} })
+	if status := do(t, http.MethodPost, srv.URL+"/join", "", &e); status != http.StatusBadGateway || e.Code != rak811.CodeJoinOtaaErr {
+		t.Errorf("got status %d, %+v", status, e)
+	}
+	module.HandleFunc("join", func(args string) []string { return []string{"OK", "at+recv=8,0,0"} })
+	if status := do(t, http.MethodPost, srv.URL+"/join", `{"mode": "otaa"}`, nil); status != http.StatusBadGateway {
+		t.Errorf("got status %d", status)
+	}
+	if status := do(t, http.MethodPost, srv.URL+"/join", `{"mode": "abp"}`, &join); status != http.StatusOK || join.Result != "success" {
+		t.Errorf("got status %d, %+v", status, join)
+	}
+}
+
+func TestServer_Uplink(t *testing.T) {
+	srv, module := newTestServer(t)
+
+	var e errorResponse
+	body := `{"port": 2, "confirmed": true, "payload": "AQI="}`
+	if status := do(t, http.MethodPost, srv.URL+"/uplinks", body, &e); status != http.StatusBadGateway || e.Code != rak811.CodeNotJoin {
+		t.Fatalf("got status %d, %+v", status, e)
+	}
+
+	var join joinResponse
+	if status := do(t, http.MethodPost, srv.URL+"/join", "", &join); status != http.StatusOK || join.Result != "success" {
+		t.Fatalf("got status %d, %+v", status, join)
+	}
+
+	var res uplinkResponse
+	if status := do(t, http.MethodPost, srv.URL+"/uplinks", body, &res); status != http.StatusOK || res.Status != "confirmed" {
+		t.Fatalf("got status %d, %+v", status, res)
+	}
+	cmds := module.Commands()
+	if last := cmds[len(cmds)-1]; last != "send=1,2,0102" {
+		t.Errorf("got %q", last)
+	}
+
+	module.QueueDownlink(simulator.Downlink{Port: 3, RSSI: -70, SNR: 5, Payload: []byte{0xca, 0xfe}})
+	res = uplinkResponse{}
+	if status := do(t, http.MethodPost, srv.URL+"/uplinks", `{"port": 2, "payload": "AQI="}`, &res); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if d := res.Downlink; res.Status != "unconfirmed" || d == nil || d.Port != 3 || d.RSSI != -70 || !bytes.Equal(d.Payload, []byte{0xca, 0xfe}) {
+		t.Errorf("got %+v", res)
+	}
+
+	if status := do(t, http.MethodPost, srv.URL+"/uplinks", `{"port": 0, "payload": "AQI="}`, nil); status != http.StatusBadRequest {
+		t.Errorf("got status %d", status)
+	}
+}
+
+func TestServer_Downlinks(t *testing.T) {
+	srv, module := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/downlinks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("got content type %q", ct)
+	}
+
+	do(t, http.MethodPost, srv.URL+"/join", `{"mode": "otaa"}`, nil)
+	module.QueueDownlink(simulator.Downlink{Port: 9, RSSI: -80, SNR: 2, Payload: []byte("hi")})
+	if status := do(t, http.MethodPost, srv.URL+"/uplinks", `{"port": 1, "payload": "AA=="}`, nil); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var event, data string
+	for scanner.Scan() && data == "" {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	var d downlink
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		t.Fatal(err)
+	}
+	if event != "downlink" || d.Port != 9 || string(d.Payload) != "hi" {
+		t.Errorf("got %s %+v", event, d)
+	}
+}
+
+func TestServer_Radio(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	var sig signalResponse
+	if status := do(t, http.MethodGet, srv.URL+"/signal", "", &sig); status != http.StatusOK || sig.RSSI != -40 || sig.SNR != 7 {
+		t.Errorf("got status %d, %+v", status, sig)
+	}
+
+	var st radioStatusResponse
+	if status := do(t, http.MethodGet, srv.URL+"/radio/status", "", &st); status != http.StatusOK {
+		t.Errorf("got status %d, %+v", status, st)
+	}
+}
@@ -0,0 +1,45 @@
+package rak811
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// configKey keys of get_config and set_config.
+var configKey = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// secretConfigKeys keys of the LoRaWAN keys, which shouldn't be read back
+// or logged.
+var secretConfigKeys = map[string]bool{"app_key": true, "nwks_key": true, "apps_key": true}
+
+// ValidateConfigKey checks the key of a get_config or set_config
+// command, lower case letters, digits and underscores, so an untrusted
+// key can't inject another command.
+func ValidateConfigKey(key string) error {
+	if !configKey.MatchString(key) {
+		return fmt.Errorf("invalid config key %q", key)
+	}
+	return nil
+}
+
+// ValidateConfigValue checks the value of a set_config command,
+// printable ASCII without the & separating the pairs, so an untrusted
+// value can't inject another pair or command.
+func ValidateConfigValue(value string) error {
+	for _, c := range value {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Errorf("invalid config value %q", value)
+		}
+	}
+	if strings.Contains(value, "&") {
+		return fmt.Errorf("invalid config value %q", value)
+	}
+	return nil
+}
+
+// IsSecretConfigKey reports whether the key holds a LoRaWAN key: app_key,
+// nwks_key or apps_key.
+func IsSecretConfigKey(key string) bool {
+	return secretConfigKeys[key]
+}
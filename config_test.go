@@ -0,0 +1,31 @@
+package rak811
+
+import "testing"
+
+func TestValidateConfig(t *testing.T) {
+	for _, key := range []string{"dev_eui", "ch_mask", "rx_delay1"} {
+		if err := ValidateConfigKey(key); err != nil {
+			t.Errorf("%q: %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "dev_eui\r\nat+reset=0", "app_eui:00", "a&b", "DEV_EUI"} {
+		if err := ValidateConfigKey(key); err == nil {
+			t.Errorf("%q: want error", key)
+		}
+	}
+
+	for _, value := range []string{"", "39d7119f920f7952", "0,FFFF", "3,869525000"} {
+		if err := ValidateConfigValue(value); err != nil {
+			t.Errorf("%q: %v", value, err)
+		}
+	}
+	for _, value := range []string{"00\r\nat+reset=0", "00\n", "00&dev_eui:00", "é"} {
+		if err := ValidateConfigValue(value); err == nil {
+			t.Errorf("%q: want error", value)
+		}
+	}
+
+	if !IsSecretConfigKey("app_key") || IsSecretConfigKey("app_eui") {
+		t.Error("wrong secret keys")
+	}
+}
@@ -0,0 +1,362 @@
+package rak811
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDutyCycleWindow period over which the airtime is accounted.
+const defaultDutyCycleWindow = time.Hour
+
+// ErrDutyCycle the transmission would exceed the duty cycle of its
+// sub-band.
+var ErrDutyCycle = errors.New("duty cycle limit reached")
+
+// DutyCycleError the transmission is not allowed before Wait.
+type DutyCycleError struct {
+	SubBand SubBand
+	Wait    time.Duration
+}
+
+func (e *DutyCycleError) Error() string {
+	return fmt.Sprintf("%v in %s, retry in %s", ErrDutyCycle, e.SubBand, e.Wait)
+}
+
+// Is matches ErrDutyCycle.
+func (e *DutyCycleError) Is(target error) bool {
+	return target == ErrDutyCycle
+}
+
+// SubBand frequency range sharing a duty cycle.
+type SubBand struct {
+	// MinFrequency and MaxFrequency in hertz, inclusive.
+	MinFrequency int
+	MaxFrequency int
+	// DutyCycle fraction of the time the sub-band may be used, e.g. 0.01.
+	DutyCycle float64
+}
+
+func (s SubBand) String() string {
+	return fmt.Sprintf("%.3f-%.3f MHz (%g%%)",
+		float64(s.MinFrequency)/1e6, float64(s.MaxFrequency)/1e6, s.DutyCycle*100)
+}
+
+// contains reports whether the frequency is in the sub-band.
+func (s SubBand) contains(frequency int) bool {
+	return frequency >= s.MinFrequency && frequency <= s.MaxFrequency
+}
+
+// DutyCycleConfig configuration of a DutyCycle.
+type DutyCycleConfig struct {
+	// Window period over which the airtime of a sub-band is accounted,
+	// defaults to an hour.
+	Window time.Duration
+	// Block waits until the transmission is allowed, otherwise a
+	// DutyCycleError is returned.
+	Block bool
+	// ConfirmedTransmissions transmissions accounted for a confirmed
+	// uplink, defaults to 1. The module retransmits the confirmed uplinks
+	// until acknowledged without reporting it, so only the first
+	// transmission is accounted unless set to the retransmissions of the
+	// module plus one.
+	ConfirmedTransmissions int
+	// Region whose sub-bands regulate the frequencies of Reserve, Used
+	// and the LoraP2P frames, defaults to EU868. The LoRaWAN uplinks are
+	// regulated by the region of the module.
+	Region Region
+}
+
+// DutyCycle accounts the airtime used in the regulated sub-bands and
+// holds back the transmissions exceeding their duty cycle over the
+// window. It can be shared by the modules using the same antenna.
+type DutyCycle struct {
+	window    time.Duration
+	block     bool
+	confirmed int
+	region    Region
+	now       func() time.Time
+
+	mu   sync.Mutex
+	used map[SubBand][]transmission
+}
+
+// transmission airtime used from a time.
+type transmission struct {
+	at      time.Time
+	airtime time.Duration
+}
+
+// NewDutyCycle creates an accountant with no airtime used.
+func NewDutyCycle(conf DutyCycleConfig) *DutyCycle {
+	if conf.Window <= 0 {
+		conf.Window = defaultDutyCycleWindow
+	}
+	if conf.ConfirmedTransmissions < 1 {
+		conf.ConfirmedTransmissions = 1
+	}
+	if conf.Region == "" {
+		conf.Region = EU868
+	}
+	return &DutyCycle{
+		window:    conf.Window,
+		block:     conf.Block,
+		confirmed: conf.ConfirmedTransmissions,
+		region:    conf.Region,
+		now:       time.Now,
+		used:      make(map[SubBand][]transmission),
+	}
+}
+
+// Used returns the airtime used in the sub-band of the frequency over
+// the window.
+func (d *DutyCycle) Used(frequency int) time.Duration {
+	sb, ok := d.region.Plan().subBand(frequency)
+	if !ok {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var used time.Duration
+	for _, t := range d.prune(sb, d.now()) {
+		used += t.airtime
+	}
+	return used
+}
+
+// Reserve accounts the airtime if allowed now, otherwise it returns the
+// wait before it is. Frequencies outside of the regulated sub-bands are
+// always allowed, airtimes exceeding the budget of the window never are
+// and the window is returned.
+func (d *DutyCycle) Reserve(frequency int, airtime time.Duration) time.Duration {
+	sb, ok := d.region.Plan().subBand(frequency)
+	if !ok {
+		return 0
+	}
+	_, wait := d.reserve(sb, airtime)
+	return wait
+}
+
+// reserve accounts the airtime in the sub-band if allowed now, returning
+// the transmission accounted, otherwise the wait before it is.
+func (d *DutyCycle) reserve(sb SubBand, airtime time.Duration) (transmission, time.Duration) {
+	budget := d.budget(sb)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	used := d.prune(sb, now)
+	var total time.Duration
+	for _, t := range used {
+		total += t.airtime
+	}
+
+	// wait for the oldest transmissions to leave the window
+	excess := total + airtime - budget
+	if excess > 0 {
+		if airtime > budget {
+			return transmission{}, d.window
+		}
+		for _, t := range used {
+			excess -= t.airtime
+			if excess <= 0 {
+				return transmission{}, t.at.Add(d.window).Sub(now)
+			}
+		}
+	}
+
+	t := transmission{at: now, airtime: airtime}
+	d.used[sb] = append(used, t)
+	return t, 0
+}
+
+// release gives back the airtime of a transmission which failed.
+func (d *DutyCycle) release(sb SubBand, t transmission) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	used := d.used[sb]
+	for i := range used {
+		if used[i] == t {
+			d.used[sb] = append(used[:i:i], used[i+1:]...)
+			return
+		}
+	}
+}
+
+// acquire reserves the airtime in the first of the sub-bands allowing
+// it, waiting until one does if configured to block or until done is
+// closed. No sub-band means an unregulated frequency. Returns the release
+// of the reservation, for a transmission which failed.
+func (d *DutyCycle) acquire(done <-chan struct{}, sbs []SubBand, airtime time.Duration) (func(), error) {
+	if len(sbs) == 0 {
+		return func() {}, nil
+	}
+
+	for {
+		var wait time.Duration
+		var sb SubBand
+		for _, s := range sbs {
+			t, w := d.reserve(s, airtime)
+			if w == 0 {
+				return func() { d.release(s, t) }, nil
+			}
+			if wait == 0 || w < wait {
+				wait, sb = w, s
+			}
+		}
+		if !d.block || airtime > d.budget(sb) {
+			return nil, &DutyCycleError{SubBand: sb, Wait: wait}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-done:
+			timer.Stop()
+			return nil, ErrDisconnected
+		}
+	}
+}
+
+// budget returns the airtime allowed in the sub-band over the window.
+func (d *DutyCycle) budget(sb SubBand) time.Duration {
+	return time.Duration(float64(d.window) * sb.DutyCycle)
+}
+
+// prune drops the transmissions out of the window, d.mu must be held.
+func (d *DutyCycle) prune(sb SubBand, now time.Time) []transmission {
+	used := d.used[sb]
+	i := 0
+	for i < len(used) && !used[i].at.Add(d.window).After(now) {
+		i++
+	}
+	used = used[i:]
+	if len(used) == 0 {
+		delete(d.used, sb)
+	} else {
+		d.used[sb] = used
+	}
+	return used
+}
+
+// subBand returns the regulated sub-band of the frequency in the plan.
+func (p ChannelPlan) subBand(frequency int) (SubBand, bool) {
+	for _, sb := range p.SubBands {
+		if sb.contains(frequency) {
+			return sb, true
+		}
+	}
+	return SubBand{}, false
+}
+
+// subBands returns the distinct regulated sub-bands of the frequencies in
+// the plan.
+func (p ChannelPlan) subBands(frequencies ...int) []SubBand {
+	var sbs []SubBand
+	for _, f := range frequencies {
+		sb, ok := p.subBand(f)
+		if ok && !slices.Contains(sbs, sb) {
+			sbs = append(sbs, sb)
+		}
+	}
+	return sbs
+}
+
+// sendAirtime reserves the airtime of the LoRaWAN uplink send=<type>,<port>,<data>
+// at the current region and data rate, cached between uplinks. The module
+// doesn't report the channel it picked among the default channels whose
+// sub-band allows the uplink, so the uplink is accounted in the first of
+// their sub-bands allowing it, and confirmed uplinks
+// ConfirmedTransmissions times. Returns the release of the reservation.
+func (l *Lora) sendAirtime(data string) (func(), error) {
+	fields := strings.SplitN(data, ",", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid send data: %q", data)
+	}
+
+	r, err := l.region()
+	if err != nil {
+		return nil, err
+	}
+	plan := r.Plan()
+	frequencies := make([]int, len(plan.Channels))
+	for i, ch := range plan.Channels {
+		frequencies[i] = ch.Frequency
+	}
+	sbs := plan.subBands(frequencies...)
+	if len(sbs) == 0 {
+		return func() {}, nil
+	}
+
+	dr, err := l.dataRate()
+	if err != nil {
+		return nil, err
+	}
+	airtime := TimeOnAir(dr.Modulation(), len(fields[2])/2+lorawanOverhead)
+	if fields[0] == "1" {
+		airtime *= time.Duration(l.dutyCycle.confirmed)
+	}
+	return l.dutyCycle.acquire(l.closing, sbs, airtime)
+}
+
+// txcAirtime reserves the airtime of the LoraP2P frames txc=<count>,<interval>,<data>
+// at the current RF config, cached until changed. Returns the release of
+// the reservation.
+func (l *Lora) txcAirtime(parameters string) (func(), error) {
+	fields := strings.SplitN(parameters, ",", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid txc parameters: %q", parameters)
+	}
+	count, err := strconv.Atoi(fields[0])
+	if err != nil || count < 1 {
+		count = 1
+	}
+
+	rf, err := l.rfConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dc := l.dutyCycle
+	airtime := rf.TimeOnAir(len(fields[2]) / 2)
+	return dc.acquire(l.closing, dc.region.Plan().subBands(rf.Frequency), time.Duration(count)*airtime)
+}
+
+// rfConfig returns the RF config of the module, read once and again after
+// SetRfConfig.
+func (l *Lora) rfConfig() (RFConfig, error) {
+	l.mu.Lock()
+	rf := l.rf
+	l.mu.Unlock()
+	if rf != nil {
+		return *rf, nil
+	}
+
+	resp, err := l.GetRfConfig()
+	if err != nil {
+		return RFConfig{}, err
+	}
+	if err := isError(resp); err != nil {
+		return RFConfig{}, err
+	}
+	c, err := ParseRFConfig(resp)
+	if err != nil {
+		return RFConfig{}, err
+	}
+	l.setRFConfig(&c)
+	return c, nil
+}
+
+func (l *Lora) setRFConfig(rf *RFConfig) {
+	l.mu.Lock()
+	l.rf = rf
+	l.mu.Unlock()
+}
@@ -0,0 +1,259 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+// newSimulatedLora connects to module over tcp with conf.
+func newSimulatedLora(t *testing.T, module *simulator.Module, conf Config) *Lora {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go module.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	conf.Name = "tcp://" + ln.Addr().String()
+	if conf.Timeout == 0 {
+		conf.Timeout = time.Second
+	}
+	l, err := New(&conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(l.Close)
+	return l
+}
+
+func TestDutyCycle_Reserve(t *testing.T) {
+	now := time.Now()
+	d := NewDutyCycle(DutyCycleConfig{})
+	d.now = func() time.Time { return now }
+
+	// 1% of an hour
+	if wait := d.Reserve(868100000, 30*time.Second); wait != 0 {
+		t.Fatalf("got wait %s", wait)
+	}
+	now = now.Add(10 * time.Minute)
+	if wait := d.Reserve(868300000, 10*time.Second); wait != 50*time.Minute {
+		t.Fatalf("got wait %s", wait)
+	}
+	if used := d.Used(868500000); used != 30*time.Second {
+		t.Errorf("got used %s", used)
+	}
+
+	// the sub-bands have their own budget
+	if wait := d.Reserve(869525000, 5*time.Minute); wait != 0 {
+		t.Errorf("got wait %s", wait)
+	}
+	if wait := d.Reserve(869000000, 4*time.Second); wait != time.Hour {
+		t.Errorf("got wait %s", wait)
+	}
+	if wait := d.Reserve(915000000, time.Hour); wait != 0 {
+		t.Errorf("got wait %s for an unregulated frequency", wait)
+	}
+
+	now = now.Add(50 * time.Minute)
+	if wait := d.Reserve(868100000, 10*time.Second); wait != 0 {
+		t.Errorf("got wait %s", wait)
+	}
+	if used := d.Used(868100000); used != 10*time.Second {
+		t.Errorf("got used %s", used)
+	}
+}
+
+func TestDutyCycle_Region(t *testing.T) {
+	// IN865 has no duty cycle, 865-868 MHz is a 1% sub-band in EU868
+	in := NewDutyCycle(DutyCycleConfig{Region: IN865})
+	if wait := in.Reserve(865062500, time.Hour); wait != 0 {
+		t.Errorf("got wait %s in IN865", wait)
+	}
+	eu := NewDutyCycle(DutyCycleConfig{})
+	if wait := eu.Reserve(865062500, time.Hour); wait != time.Hour {
+		t.Errorf("got wait %s in EU868", wait)
+	}
+}
+
+func TestDutyCycle_AcquireSubBands(t *testing.T) {
+	d := NewDutyCycle(DutyCycleConfig{Window: time.Minute})
+	sbs := EU868.Plan().subBands(868100000, 869525000, 868300000)
+	if len(sbs) != 2 {
+		t.Fatalf("got %v", sbs)
+	}
+
+	// budgets of 600ms then 6s, the second frame goes to the next sub-band
+	if _, err := d.acquire(nil, sbs, 500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	release, err := d.acquire(nil, sbs, 500*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := d.Used(868100000), d.Used(869525000); a != 500*time.Millisecond || b != 500*time.Millisecond {
+		t.Errorf("got used %s and %s", a, b)
+	}
+
+	release()
+	if used := d.Used(869525000); used != 0 {
+		t.Errorf("got used %s after release", used)
+	}
+}
+
+func TestLora_DutyCycleReleased(t *testing.T) {
+	module := simulator.New()
+	dc := NewDutyCycle(DutyCycleConfig{})
+	l := newSimulatedLora(t, module, Config{DutyCycle: dc})
+
+	// not joined, the uplink isn't sent
+	if _, err := l.Send("0,1,0102"); err == nil {
+		t.Fatal("want error before joining")
+	}
+	// not in LoraP2P mode, the frames aren't sent
+	for i := 0; i < 2; i++ {
+		if resp, err := l.Txc("1,0,01"); err != nil || isError(resp) == nil {
+			t.Fatalf("got %q, %v", resp, err)
+		}
+	}
+	if used := dc.Used(868100000); used != 0 {
+		t.Errorf("got used %s", used)
+	}
+
+	// the RF config is read once
+	want := []string{"band", "dr", "send=0,1,0102", "rf_config", "txc=1,0,01", "txc=1,0,01"}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
+
+func TestLora_SendDutyCycle(t *testing.T) {
+	module := simulator.New()
+	dc := NewDutyCycle(DutyCycleConfig{Window: 10 * time.Second})
+	l := newSimulatedLora(t, module, Config{DutyCycle: dc})
+
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+
+	// DR5 frames of 46ms, with a budget of 100ms
+	for i := 0; i < 2; i++ {
+		if _, err := l.Send("0,1,0102"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := l.Send("0,1,0102")
+	var derr *DutyCycleError
+	if !errors.As(err, &derr) || !errors.Is(err, ErrDutyCycle) {
+		t.Fatalf("got %v", err)
+	}
+	if derr.Wait <= 0 || derr.Wait > 10*time.Second || derr.SubBand.DutyCycle != 0.01 {
+		t.Errorf("got %+v", derr)
+	}
+
+	// the data rate is read once
+	want := []string{"join=otaa", "band", "dr", "send=0,1,0102", "send=0,1,0102"}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
+
+func TestLora_SendDataRateCache(t *testing.T) {
+	module := simulator.New()
+	dc := NewDutyCycle(DutyCycleConfig{ConfirmedTransmissions: 3})
+	l := newSimulatedLora(t, module, Config{DutyCycle: dc})
+
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.SetDataRate(5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Send("0,1,0102"); err != nil {
+		t.Fatal(err)
+	}
+	// a downlink may carry an ADR command, the data rate is read again
+	module.QueueDownlink(simulator.Downlink{Port: 7, Payload: []byte{1}})
+	if _, err := l.Send("0,1,0102"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Send("1,1,0102"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"join=otaa", "band", "dr=5", "send=0,1,0102", "send=0,1,0102", "dr", "send=1,1,0102"}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+
+	// two DR5 uplinks of 46ms and a confirmed one accounted three times
+	airtime := TimeOnAir(EU868.Plan().DataRates[5].Modulation(), 2+lorawanOverhead)
+	if used := dc.Used(868100000); used != 5*airtime {
+		t.Errorf("got used %s, want %s", used, 5*airtime)
+	}
+}
+
+func TestLora_TxcDutyCycle(t *testing.T) {
+	module := simulator.New()
+	dc := NewDutyCycle(DutyCycleConfig{Window: time.Minute, Block: true})
+	l := newSimulatedLora(t, module, Config{DutyCycle: dc})
+
+	// a SF12 frame doesn't fit in the 600ms budget, it isn't waited for
+	if _, err := l.Txc("1,0,01"); !errors.Is(err, ErrDutyCycle) {
+		t.Fatalf("got %v", err)
+	}
+	if cmds := module.Commands(); !equalStrings(cmds, []string{"rf_config"}) {
+		t.Errorf("got %q", cmds)
+	}
+}
+
+func TestP2P_TransmitDutyCycle(t *testing.T) {
+	module := simulator.New()
+	dc := NewDutyCycle(DutyCycleConfig{Window: 500 * time.Millisecond, Block: true})
+	l := newSimulatedLora(t, module, Config{DutyCycle: dc})
+
+	// frames of 31ms in the 10% sub-band, with a budget of 50ms
+	conf := RFConfig{Frequency: 869525000, SpreadingFactor: 7, Bandwidth: BW125, CodingRate: CR4_5, Preamble: 8, Power: 14}
+	p, err := NewP2P(l, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := p.Transmit(ctx, []byte{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("second frame sent after %s", elapsed)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Transmit(short, []byte{1, 2}); err != context.DeadlineExceeded {
+		t.Errorf("got %v", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
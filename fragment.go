@@ -0,0 +1,272 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// FragmentHeaderSize bytes prepended to every fragment:
+	// message id, fragment index and fragment count.
+	FragmentHeaderSize = 3
+	// MaxFragments maximum number of fragments of a message.
+	MaxFragments = 255
+
+	// defaultReassemblyTimeout wait for the missing fragments of a
+	// message when no timeout is given.
+	defaultReassemblyTimeout = 10 * time.Second
+)
+
+// ErrMessageTooLarge the message needs more than MaxFragments fragments.
+var ErrMessageTooLarge = errors.New("message exceeds the maximum number of fragments")
+
+// Fragmenter splits messages larger than the link MTU into numbered
+// fragments and sends them in order.
+type Fragmenter struct {
+	link Link
+	mtu  int
+
+	mu sync.Mutex
+	id uint8
+}
+
+// NewFragmenter creates a fragmenter sending frames of at most mtu bytes,
+// header included.
+func NewFragmenter(link Link, mtu int) (*Fragmenter, error) {
+	if mtu <= FragmentHeaderSize {
+		return nil, fmt.Errorf("invalid mtu: %d", mtu)
+	}
+	return &Fragmenter{
+		link: link,
+		mtu:  mtu,
+	}, nil
+}
+
+// Send fragments the message and transmits every fragment, stopping at
+// the first failure.
+func (f *Fragmenter) Send(ctx context.Context, msg []byte) error {
+	fragments, err := f.fragment(msg)
+	if err != nil {
+		return err
+	}
+
+	for i, fragment := range fragments {
+		if err := f.link.Transmit(ctx, fragment); err != nil {
+			return fmt.Errorf("failed to send fragment %d/%d: %v", i+1, len(fragments), err)
+		}
+	}
+	return nil
+}
+
+func (f *Fragmenter) fragment(msg []byte) ([][]byte, error) {
+	if len(msg) == 0 {
+		return nil, ErrEmptyPayload
+	}
+
+	size := f.mtu - FragmentHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > MaxFragments {
+		return nil, ErrMessageTooLarge
+	}
+
+	f.mu.Lock()
+	id := f.id
+	f.id++
+	f.mu.Unlock()
+
+	fragments := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		fragment := append([]byte{id, byte(i), byte(count)}, msg[i*size:end]...)
+		fragments = append(fragments, fragment)
+	}
+	return fragments, nil
+}
+
+// Bitmap one bit per fragment, set when the fragment was received.
+type Bitmap []byte
+
+func newBitmap(count int) Bitmap {
+	return make(Bitmap, (count+7)/8)
+}
+
+// Has reports whether fragment i is set.
+func (b Bitmap) Has(i int) bool {
+	return i/8 < len(b) && b[i/8]&(1<<uint(i%8)) != 0
+}
+
+func (b Bitmap) set(i int) {
+	b[i/8] |= 1 << uint(i%8)
+}
+
+// Missing returns the indexes of the first count fragments not set.
+func (b Bitmap) Missing(count int) []int {
+	var missing []int
+	for i := 0; i < count; i++ {
+		if !b.Has(i) {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// Message a reassembled message. An incomplete message is delivered when
+// its fragments don't all arrive before the timeout.
+type Message struct {
+	ID      uint8
+	Payload []byte
+	// Count number of fragments of the message.
+	Count int
+	// Received fragments that arrived.
+	Received Bitmap
+	// RSSI and SNR of the last fragment received.
+	RSSI int
+	SNR  int
+}
+
+// Complete reports whether every fragment was received.
+func (m Message) Complete() bool {
+	return len(m.Received.Missing(m.Count)) == 0
+}
+
+type partialMessage struct {
+	count     int
+	received  Bitmap
+	fragments [][]byte
+	deadline  time.Time
+	last      Frame
+}
+
+// Reassembler rebuilds messages from the fragments received on a link.
+type Reassembler struct {
+	frames   <-chan Frame
+	timeout  time.Duration
+	messages chan Message
+	partial  map[uint8]*partialMessage
+	// completed expiry of the IDs of the messages delivered, whose
+	// retransmitted fragments are dropped
+	completed map[uint8]time.Time
+}
+
+// NewReassembler starts reassembling the fragments read from frames,
+// typically Link.Receive. A message is given up timeout after its last
+// fragment was received, defaults to 10s. The fragments of a message
+// received again within the timeout after it was completed are dropped.
+func NewReassembler(frames <-chan Frame, timeout time.Duration) *Reassembler {
+	if timeout <= 0 {
+		timeout = defaultReassemblyTimeout
+	}
+	r := &Reassembler{
+		frames:    frames,
+		timeout:   timeout,
+		messages:  make(chan Message, frameBacklog),
+		partial:   make(map[uint8]*partialMessage),
+		completed: make(map[uint8]time.Time),
+	}
+	go r.run()
+	return r
+}
+
+// Messages returns the channel where reassembled messages are delivered.
+// The channel is closed when the frames channel is closed.
+func (r *Reassembler) Messages() <-chan Message {
+	return r.messages
+}
+
+func (r *Reassembler) run() {
+	defer close(r.messages)
+
+	// checked twice per timeout, at most every millisecond
+	ticker := time.NewTicker(max(r.timeout/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case frame, ok := <-r.frames:
+			if !ok {
+				return
+			}
+			r.add(frame, time.Now())
+		case now := <-ticker.C:
+			r.expire(now)
+		}
+	}
+}
+
+func (r *Reassembler) add(frame Frame, now time.Time) {
+	if len(frame.Payload) < FragmentHeaderSize {
+		return
+	}
+
+	id, index, count := frame.Payload[0], int(frame.Payload[1]), int(frame.Payload[2])
+	if count == 0 || index >= count {
+		return
+	}
+	if until, ok := r.completed[id]; ok && now.Before(until) {
+		return
+	}
+	delete(r.completed, id)
+
+	msg, ok := r.partial[id]
+	if !ok || msg.count != count {
+		msg = &partialMessage{
+			count:     count,
+			received:  newBitmap(count),
+			fragments: make([][]byte, count),
+		}
+		r.partial[id] = msg
+	}
+
+	if !msg.received.Has(index) {
+		msg.received.set(index)
+		msg.fragments[index] = frame.Payload[FragmentHeaderSize:]
+	}
+	msg.deadline = now.Add(r.timeout)
+	msg.last = frame
+
+	if len(msg.received.Missing(count)) > 0 {
+		return
+	}
+
+	var payload []byte
+	for _, fragment := range msg.fragments {
+		payload = append(payload, fragment...)
+	}
+	delete(r.partial, id)
+	r.completed[id] = now.Add(r.timeout)
+	r.deliver(id, msg, payload)
+}
+
+func (r *Reassembler) expire(now time.Time) {
+	for id, msg := range r.partial {
+		if now.After(msg.deadline) {
+			delete(r.partial, id)
+			r.deliver(id, msg, nil)
+		}
+	}
+	for id, until := range r.completed {
+		if !now.Before(until) {
+			delete(r.completed, id)
+		}
+	}
+}
+
+func (r *Reassembler) deliver(id uint8, msg *partialMessage, payload []byte) {
+	select {
+	case r.messages <- Message{
+		ID:       id,
+		Payload:  payload,
+		Count:    msg.count,
+		Received: msg.received,
+		RSSI:     msg.last.RSSI,
+		SNR:      msg.last.SNR,
+	}:
+	default:
+	}
+}
@@ -0,0 +1,132 @@
+package rak811
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFragmenter_Send(t *testing.T) {
+	medium := newMemMedium(nil)
+	tx, rx := medium.Link(), medium.Link()
+
+	fragmenter, err := NewFragmenter(tx, 51)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	reassembler := NewReassembler(rx.Receive(), time.Second)
+
+	blob := make([]byte, 1024)
+	for i := range blob {
+		blob[i] = byte(i)
+	}
+
+	if err := fragmenter.Send(context.Background(), blob); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	select {
+	case msg := <-reassembler.Messages():
+		if !msg.Complete() || msg.Count != 22 {
+			t.Fatalf("got incomplete message, missing %v", msg.Received.Missing(msg.Count))
+		}
+		if !bytes.Equal(msg.Payload, blob) {
+			t.Errorf("payload mismatch")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+}
+
+func TestFragmenter_Send_TooLarge(t *testing.T) {
+	fragmenter, _ := NewFragmenter(newMemMedium(nil).Link(), 4)
+	if err := fragmenter.Send(context.Background(), make([]byte, MaxFragments+1)); err != ErrMessageTooLarge {
+		t.Errorf("got %v, want %v", err, ErrMessageTooLarge)
+	}
+}
+
+func TestReassembler_Timeout(t *testing.T) {
+	// lose the second and fourth fragments
+	medium := newMemMedium(func(n int) bool { return n == 2 || n == 4 })
+	tx, rx := medium.Link(), medium.Link()
+
+	fragmenter, _ := NewFragmenter(tx, 13)
+	reassembler := NewReassembler(rx.Receive(), 50*time.Millisecond)
+
+	if err := fragmenter.Send(context.Background(), make([]byte, 50)); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	select {
+	case msg := <-reassembler.Messages():
+		if msg.Complete() || msg.Payload != nil {
+			t.Fatal("want incomplete message")
+		}
+		missing := msg.Received.Missing(msg.Count)
+		if len(missing) != 2 || missing[0] != 1 || missing[1] != 3 {
+			t.Errorf("got missing %v, want [1 3]", missing)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+}
+
+func TestReassembler_LateFragment(t *testing.T) {
+	medium := newMemMedium(nil)
+	tx, rx := medium.Link(), medium.Link()
+	reassembler := NewReassembler(rx.Receive(), 100*time.Millisecond)
+
+	// the fragment is retransmitted after the message was completed
+	_ = tx.Transmit(context.Background(), []byte{7, 0, 1, 0xaa})
+	_ = tx.Transmit(context.Background(), []byte{7, 0, 1, 0xaa})
+	if msg := nextMessage(t, reassembler.Messages()); !msg.Complete() || msg.Payload[0] != 0xaa {
+		t.Fatalf("got %+v", msg)
+	}
+	select {
+	case msg := <-reassembler.Messages():
+		t.Fatalf("got late message %+v", msg)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// the ID is reused past the timeout
+	_ = tx.Transmit(context.Background(), []byte{7, 0, 1, 0xbb})
+	if msg := nextMessage(t, reassembler.Messages()); !msg.Complete() || msg.Payload[0] != 0xbb {
+		t.Errorf("got %+v", msg)
+	}
+}
+
+func nextMessage(t *testing.T, messages <-chan Message) Message {
+	t.Helper()
+
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+	return Message{}
+}
+
+func TestReassembler_InvalidTimeout(t *testing.T) {
+	// non-positive and tiny timeouts don't stop the reassembler
+	for _, timeout := range []time.Duration{-time.Second, 0, time.Nanosecond} {
+		medium := newMemMedium(nil)
+		tx, rx := medium.Link(), medium.Link()
+
+		fragmenter, _ := NewFragmenter(tx, 13)
+		reassembler := NewReassembler(rx.Receive(), timeout)
+		if err := fragmenter.Send(context.Background(), []byte{1}); err != nil {
+			t.Fatalf("error %v", err)
+		}
+
+		select {
+		case msg := <-reassembler.Messages():
+			if !msg.Complete() {
+				t.Errorf("timeout %s: got incomplete message", timeout)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout %s: no message", timeout)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package rak811
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+const (
+	// maxLineLength longest line accepted from the module, a full P2P
+	// frame event is a little over 512 bytes.
+	maxLineLength = 1024
+
+	readChunkSize = 256
+)
+
+var errLineTooLong = errors.New("line too long")
+
+// lineScanner splits the module output into lines. It lives as long as
+// the port, so bytes read past the end of a line are kept for the next
+// one. Lines end with \r\n, \n or a lone \r.
+type lineScanner struct {
+	r     io.Reader
+	buf   []byte
+	chunk []byte
+
+	// skipLF the last line ended with \r at the end of the buffer, a \n
+	// at the start of the next read belongs to it.
+	skipLF bool
+	// discard the rest of an over-long line is being dropped.
+	discard bool
+	// read bytes read from r so far, also loaded by the command in
+	// progress while the background reader reads.
+	read atomic.Int64
+}
+
+func newLineScanner(r io.Reader) *lineScanner {
+	return &lineScanner{
+		r:     r,
+		chunk: make([]byte, readChunkSize),
+	}
+}
+
+// next returns the next line without its terminator. On read errors the
+// partial line stays buffered, see pending. Over-long lines are dropped
+// and reported with errLineTooLong.
+func (s *lineScanner) next() (string, error) {
+	for {
+		line, ok, err := s.scan()
+		if err != nil || ok {
+			return line, err
+		}
+
+		n, err := s.r.Read(s.chunk)
+		s.read.Add(int64(n))
+		s.buf = append(s.buf, s.chunk[:n]...)
+		if err != nil {
+			if n > 0 {
+				if line, ok, _ := s.scan(); ok {
+					return line, nil
+				}
+			}
+			return "", err
+		}
+	}
+}
+
+// scan extracts a line from the buffer.
+func (s *lineScanner) scan() (string, bool, error) {
+	for {
+		if s.skipLF && len(s.buf) > 0 {
+			if s.buf[0] == '\n' {
+				s.buf = s.buf[1:]
+			}
+			s.skipLF = false
+		}
+
+		i := bytes.IndexAny(s.buf, "\r\n")
+		if i < 0 {
+			if len(s.buf) > maxLineLength {
+				s.buf = s.buf[:0]
+				if !s.discard {
+					s.discard = true
+					return "", false, errLineTooLong
+				}
+			}
+			return "", false, nil
+		}
+
+		line := string(s.buf[:i])
+		end := i + 1
+		if s.buf[i] == '\r' {
+			if end < len(s.buf) {
+				if s.buf[end] == '\n' {
+					end++
+				}
+			} else {
+				s.skipLF = true
+			}
+		}
+		s.buf = s.buf[end:]
+
+		if s.discard {
+			s.discard = false
+			continue
+		}
+		if len(line) > maxLineLength {
+			return "", false, errLineTooLong
+		}
+		return line, true, nil
+	}
+}
+
+// pending returns the partial line buffered so far.
+func (s *lineScanner) pending() string {
+	if s.discard {
+		return ""
+	}
+	return string(s.buf)
+}
+
+// reset drops the partial line.
+func (s *lineScanner) reset() {
+	s.buf = s.buf[:0]
+	s.discard = false
+}
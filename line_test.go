@@ -0,0 +1,84 @@
+package rak811
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineScanner_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		lines  []string
+	}{
+		{"crlf", []string{"OK\r\nat+recv=3,0,0\r\n"}, []string{"OK", "at+recv=3,0,0"}},
+		{"lf", []string{"OK\nERROR-1\n"}, []string{"OK", "ERROR-1"}},
+		{"lone cr", []string{"OK\rat+recv=2,0,0\r"}, []string{"OK", "at+recv=2,0,0"}},
+		{"partial", []string{"O", "K2.0", ".3.0\r", "\nat+recv", "=3,0,0\r\n"}, []string{"OK2.0.3.0", "at+recv=3,0,0"}},
+		{"empty", []string{"\r\n\r\nOK\r\n"}, []string{"", "", "OK"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newLineScanner(&chunkReader{chunks: tt.chunks})
+			for _, want := range tt.lines {
+				line, err := s.next()
+				if err != nil {
+					t.Fatalf("error %v", err)
+				}
+				if line != want {
+					t.Errorf("got %q, want %q", line, want)
+				}
+			}
+			if line, err := s.next(); err != io.EOF {
+				t.Errorf("got %q, %v, want EOF", line, err)
+			}
+		})
+	}
+}
+
+func TestLineScanner_Next_TooLong(t *testing.T) {
+	long := strings.Repeat("A", 3*maxLineLength)
+	s := newLineScanner(&chunkReader{chunks: []string{long[:maxLineLength], long[maxLineLength:] + "\r\nOK\r\n"}})
+
+	if _, err := s.next(); err != errLineTooLong {
+		t.Fatalf("got %v, want %v", err, errLineTooLong)
+	}
+	line, err := s.next()
+	if err != nil || line != "OK" {
+		t.Errorf("got %q, %v, want %q", line, err, "OK")
+	}
+}
+
+func TestLineScanner_Pending(t *testing.T) {
+	s := newLineScanner(&chunkReader{chunks: []string{"OK"}})
+
+	if _, err := s.next(); err != io.EOF {
+		t.Fatalf("got %v, want EOF", err)
+	}
+	if s.pending() != "OK" {
+		t.Errorf("got %q, want %q", s.pending(), "OK")
+	}
+	s.reset()
+	if s.pending() != "" {
+		t.Errorf("got %q, want empty", s.pending())
+	}
+}
+
+// chunkReader returns a chunk per read, like a serial port does.
+type chunkReader struct {
+	chunks []string
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.chunks[0])
+	c.chunks[0] = c.chunks[0][n:]
+	if c.chunks[0] == "" {
+		c.chunks = c.chunks[1:]
+	}
+	return n, nil
+}
@@ -0,0 +1,92 @@
+package rak811
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Link sends and receives frames over the radio. P2P and LoRaWANLink
+// implement it, so the layers built on top of a link work for both.
+type Link interface {
+	// Transmit sends the payload in a single frame.
+	Transmit(ctx context.Context, payload []byte) error
+	// Receive returns the channel where received frames are delivered.
+	Receive() <-chan Frame
+}
+
+// LoRaWANLink sends uplinks on a fixed port and delivers the downlinks
+// reported in the send responses.
+type LoRaWANLink struct {
+	lora      *Lora
+	port      uint8
+	confirmed bool
+
+	mu     sync.Mutex
+	frames chan Frame
+}
+
+// NewLoRaWANLink creates a link that sends its uplinks to port. The
+// module must already have joined the network.
+func NewLoRaWANLink(l *Lora, port uint8, confirmed bool) *LoRaWANLink {
+	return &LoRaWANLink{
+		lora:      l,
+		port:      port,
+		confirmed: confirmed,
+		frames:    make(chan Frame, frameBacklog),
+	}
+}
+
+// Transmit sends the payload as an uplink. Cancelling the context
+// doesn't interrupt an uplink already handed to the module.
+func (w *LoRaWANLink) Transmit(ctx context.Context, payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyPayload
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	confirmed := 0
+	if w.confirmed {
+		confirmed = 1
+	}
+
+	resp, err := w.lora.Send(fmt.Sprintf("%d,%d,%X", confirmed, w.port, payload))
+	if err != nil {
+		if lerr := WhichError(resp); lerr != nil {
+			return lerr
+		}
+		return err
+	}
+
+	evt := WhichEventResponse(resp)
+	if evt == nil {
+		return fmt.Errorf("invalid send response: %q", resp)
+	}
+
+	switch evt.Code() {
+	case StatusTxConfirmed, StatusTxUnconfirmed:
+		return nil
+	case StatusRecvData:
+		frame, err := ParseFrame(resp)
+		if err != nil {
+			return err
+		}
+		select {
+		case w.frames <- *frame:
+		default:
+		}
+		return nil
+	}
+	return fmt.Errorf("send failed: %s", evt.Description())
+}
+
+// Receive returns the channel where downlinks are delivered. Downlinks
+// are dropped if the channel isn't drained.
+func (w *LoRaWANLink) Receive() <-chan Frame {
+	return w.frames
+}
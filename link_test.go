@@ -0,0 +1,100 @@
+package rak811
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestLoRaWANLink_Transmit(t *testing.T) {
+	fsp := newFakeSerialConn([]byte(OK+CrLf), []byte("at+recv=0,2,-60,5,2:0102"+CrLf))
+	lora, _ := newLora(fsp)
+	link := NewLoRaWANLink(lora, 2, false)
+
+	if err := link.Transmit(context.Background(), []byte{0xca, 0xfe}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	frame := <-link.Receive()
+	if frame.Port != 2 || !bytes.Equal(frame.Payload, []byte{1, 2}) {
+		t.Errorf("got %+v", frame)
+	}
+}
+
+func TestLoRaWANLink_Transmit_Timeout(t *testing.T) {
+	fsp := newFakeSerialConn([]byte(OK+CrLf), []byte("at+recv=5,0,0"+CrLf))
+	lora, _ := newLora(fsp)
+	link := NewLoRaWANLink(lora, 2, true)
+
+	if err := link.Transmit(context.Background(), []byte{0xca, 0xfe}); err == nil {
+		t.Error("want error on tx timeout")
+	}
+}
+
+// memLink in-memory radio, every frame transmitted is received by the
+// peers of the same medium with the same modulation unless the drop
+// function says otherwise.
+type memLink struct {
+	medium *memMedium
+	frames chan Frame
+	config RFConfig
+}
+
+type memMedium struct {
+	mu    sync.Mutex
+	links []*memLink
+	sent  int
+	drop  func(n int) bool
+}
+
+func newMemMedium(drop func(n int) bool) *memMedium {
+	return &memMedium{drop: drop}
+}
+
+func (m *memMedium) Link() *memLink {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	link := &memLink{medium: m, frames: make(chan Frame, 256)}
+	m.links = append(m.links, link)
+	return link
+}
+
+func (l *memLink) Transmit(ctx context.Context, payload []byte) error {
+	m := l.medium
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent++
+	if m.drop != nil && m.drop(m.sent) {
+		return nil
+	}
+	for _, peer := range m.links {
+		if peer == l || !sameModulation(peer.config, l.config) {
+			continue
+		}
+		select {
+		case peer.frames <- Frame{Payload: append([]byte(nil), payload...)}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (l *memLink) Receive() <-chan Frame {
+	return l.frames
+}
+
+func (l *memLink) Config() RFConfig {
+	l.medium.mu.Lock()
+	defer l.medium.mu.Unlock()
+	return l.config
+}
+
+func (l *memLink) SetConfig(ctx context.Context, config RFConfig) error {
+	l.medium.mu.Lock()
+	defer l.medium.mu.Unlock()
+	l.config = config
+	return nil
+}
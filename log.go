@@ -0,0 +1,111 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// secretConfig matches the keys set with set_config, so they aren't logged.
+var secretConfig = regexp.MustCompile(`(app_key|nwks_key|apps_key):[0-9A-Fa-f]+`)
+
+// redact hides the secrets of a command.
+func redact(cmd string) string {
+	if !strings.HasPrefix(cmd, "set_config=") {
+		return cmd
+	}
+	return secretConfig.ReplaceAllString(cmd, "$1:<redacted>")
+}
+
+// redactReply hides the secret returned by get_config for a LoRaWAN key,
+// error replies are kept.
+func redactReply(cmd, resp string) string {
+	key, ok := strings.CutPrefix(cmd, "get_config=")
+	if !ok || !secretConfigKeys[key] || !isOk(resp) {
+		return resp
+	}
+	return OK + "<redacted>"
+}
+
+// discardHandler drops every record, used when no logger is configured.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// newLogger returns the logger of the config, or one discarding
+// everything.
+func newLogger(conf *Config) *slog.Logger {
+	if conf != nil && conf.Logger != nil {
+		return conf.Logger
+	}
+	return slog.New(discardHandler{})
+}
+
+// debugLogger logger used by Debug(true) when none is configured.
+func debugLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// errorCode returns the module error code of a command, reported either
+// as the response or as the error.
+func errorCode(resp string, err error) (int, bool) {
+	var lerr *LoraError
+	if errors.As(err, &lerr) {
+		return lerr.Code(), true
+	}
+	if lerr := WhichError(resp); lerr != nil {
+		return lerr.Code(), true
+	}
+	if err != nil {
+		if lerr := WhichError(err.Error()); lerr != nil {
+			return lerr.Code(), true
+		}
+	}
+	return 0, false
+}
+
+// logCommand logs the outcome of a command exchange.
+func (l *Lora) logCommand(cmd, resp string, err error, duration time.Duration) {
+	attrs := []slog.Attr{
+		slog.String("command", commandName(cmd)),
+		slog.String("request", redact(cmd)),
+		slog.Duration("duration", duration),
+	}
+	if resp != "" {
+		attrs = append(attrs, slog.String("reply", redactReply(cmd, resp)))
+	}
+
+	code, failed := errorCode(resp, err)
+	if failed {
+		attrs = append(attrs, slog.Int("error_code", code))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	level := slog.LevelDebug
+	if err != nil || failed {
+		level = slog.LevelWarn
+	}
+	l.log.LogAttrs(context.Background(), level, "command", attrs...)
+}
+
+// logEvent logs an at+recv event reported by the module.
+func (l *Lora) logEvent(line string) {
+	evt := WhichEventResponse(line)
+	if evt == nil {
+		return
+	}
+	l.log.LogAttrs(context.Background(), slog.LevelDebug, "event",
+		slog.Int("status", evt.Code()),
+		slog.String("description", evt.Description()),
+		slog.String("event", line),
+	)
+}
@@ -0,0 +1,81 @@
+package rak811
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"set_config=app_eui:39d7119f920f7952&app_key:a6b08140dae1d795ebfa5a6dee1f4dbd",
+			"set_config=app_eui:39d7119f920f7952&app_key:<redacted>"},
+		{"set_config=nwks_key:00112233&apps_key:44556677", "set_config=nwks_key:<redacted>&apps_key:<redacted>"},
+		{"send=0,2,app_key:00", "send=0,2,app_key:00"},
+	}
+
+	for _, tt := range tests {
+		if actual := redact(tt.in); actual != tt.out {
+			t.Errorf("got %q, want %q", actual, tt.out)
+		}
+	}
+}
+
+func TestRedactReply(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		resp string
+		out  string
+	}{
+		{"get_config=app_key", "OKa6b08140dae1d795ebfa5a6dee1f4dbd", "OK<redacted>"},
+		{"get_config=nwks_key", "OK00112233", "OK<redacted>"},
+		{"get_config=apps_key", "ERROR-2", "ERROR-2"},
+		{"get_config=app_eui", "OK39d7119f920f7952", "OK39d7119f920f7952"},
+		{"version", "OK2.0.3.0", "OK2.0.3.0"},
+	}
+
+	for _, tt := range tests {
+		if actual := redactReply(tt.cmd, tt.resp); actual != tt.out {
+			t.Errorf("%s: got %q, want %q", tt.cmd, actual, tt.out)
+		}
+	}
+}
+
+func TestLora_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	fsp := newFakeSerialConn([]byte("OK"+CrLf), []byte("ERROR-4"+CrLf))
+	lora, _ := newLora(fsp)
+	lora.log = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	if _, err := lora.SetConfig("app_key:a6b08140dae1d795ebfa5a6dee1f4dbd"); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := lora.JoinOTAA(); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	var records []map[string]interface{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec map[string]interface{}
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		records = append(records, rec)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0]["command"] != "set_config" || records[0]["request"] != "set_config=app_key:<redacted>" ||
+		records[0]["level"] != "DEBUG" || records[0]["duration"] == nil {
+		t.Errorf("got %v", records[0])
+	}
+	if records[1]["command"] != "join" || records[1]["error_code"] != float64(CodeJoinOtaaErr) || records[1]["level"] != "WARN" {
+		t.Errorf("got %v", records[1])
+	}
+}
@@ -0,0 +1,523 @@
+package rak811
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultScanInterval = 2 * time.Second
+
+var (
+	// ErrUnknownDevice no module with the DevEUI is managed.
+	ErrUnknownDevice = errors.New("unknown device")
+	// ErrNoDevice no module is available for the application.
+	ErrNoDevice = errors.New("no device available")
+
+	// errSharedConfig the template of the modules sets a field they can't
+	// share.
+	errSharedConfig = errors.New("the Capture, DutyCycle and Router of the modules must be set with Configure")
+)
+
+// ManagerConfig configuration of a Manager.
+type ManagerConfig struct {
+	// Pattern glob matching the serial devices to manage, e.g.
+	// /dev/ttyUSB*. The devices are only added with Add when empty.
+	Pattern string
+	// ScanInterval how often Pattern is matched, defaults to 2s.
+	ScanInterval time.Duration
+	// Config template of the modules, Name is set to the device path. It
+	// is copied to every module, so Capture, DutyCycle and Router, which
+	// the modules can't share, must be set by Configure instead.
+	Config Config
+	// Configure sets the fields of the config of the module opened at
+	// name which aren't shared, e.g. its Capture, DutyCycle or Router.
+	Configure func(name string, conf *Config)
+}
+
+// ManagerEventType kind of ManagerEvent.
+type ManagerEventType int
+
+const (
+	// DeviceAdded a module was opened and identified.
+	DeviceAdded ManagerEventType = iota
+	// DeviceRemoved a module was closed.
+	DeviceRemoved
+	// DeviceEvent a module reported an at+recv event.
+	DeviceEvent
+)
+
+func (t ManagerEventType) String() string {
+	switch t {
+	case DeviceAdded:
+		return "added"
+	case DeviceRemoved:
+		return "removed"
+	case DeviceEvent:
+		return "event"
+	}
+	return "unknown"
+}
+
+// ManagerEvent a module was added or removed, or reported an at+recv
+// event with its status and frame.
+type ManagerEvent struct {
+	Type   ManagerEventType
+	DevEUI string
+	Status int
+	Frame  *Frame
+}
+
+// Device a module of a Manager. Its commands are serialized by Do.
+type Device struct {
+	name   string
+	devEUI string
+	appEUI string
+	lora   *Lora
+
+	mu sync.Mutex
+}
+
+// Name returns the port of the module.
+func (d *Device) Name() string {
+	return d.name
+}
+
+// DevEUI returns the DevEUI identifying the module, in lower case.
+func (d *Device) DevEUI() string {
+	return d.devEUI
+}
+
+// AppEUI returns the application of the module, in lower case.
+func (d *Device) AppEUI() string {
+	return d.appEUI
+}
+
+// Do runs fn with the module, one call at a time.
+func (d *Device) Do(fn func(l *Lora) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return fn(d.lora)
+}
+
+// Manager opens several modules, identified by their DevEUI. It adds and
+// removes the serial devices matching a pattern as they appear and
+// disappear, and balances uplinks across the modules of an application.
+type Manager struct {
+	conf ManagerConfig
+	log  *slog.Logger
+
+	open func(conf *Config) (*Lora, error)
+	glob func(pattern string) ([]string, error)
+
+	mu      sync.Mutex
+	devices map[string]*Device // by DevEUI
+	names   map[string]string  // DevEUI by port
+	opening map[string]bool
+	next    map[string]int // round robin position by AppEUI
+	closed  bool
+	// ops Add and Remove calls in progress, waited for by Close before
+	// closing events.
+	ops sync.WaitGroup
+
+	events chan ManagerEvent
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// NewManager creates a manager, scanning for devices if a pattern is
+// configured.
+func NewManager(conf *ManagerConfig) *Manager {
+	return newManager(conf, New, filepath.Glob)
+}
+
+func newManager(conf *ManagerConfig, open func(*Config) (*Lora, error), glob func(string) ([]string, error)) *Manager {
+	m := &Manager{
+		conf:    *conf,
+		log:     newLogger(&conf.Config),
+		open:    open,
+		glob:    glob,
+		devices: make(map[string]*Device),
+		names:   make(map[string]string),
+		opening: make(map[string]bool),
+		next:    make(map[string]int),
+		events:  make(chan ManagerEvent, frameBacklog),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if m.conf.ScanInterval <= 0 {
+		m.conf.ScanInterval = defaultScanInterval
+	}
+
+	if m.conf.Pattern == "" {
+		close(m.done)
+		return m
+	}
+	go m.watch()
+	return m
+}
+
+// Events returns the channel where the device events are delivered.
+// Events are dropped if the channel isn't drained. The channel is closed
+// when the manager is closed.
+func (m *Manager) Events() <-chan ManagerEvent {
+	return m.events
+}
+
+// Add opens the port name and identifies the module.
+func (m *Manager) Add(name string) (*Device, error) {
+	if err := m.begin(); err != nil {
+		return nil, err
+	}
+	defer m.ops.Done()
+
+	conf := m.conf.Config
+	if conf.Capture != nil || conf.DutyCycle != nil || conf.Router != nil {
+		return nil, errSharedConfig
+	}
+	conf.Name = name
+	if m.conf.Configure != nil {
+		m.conf.Configure(name, &conf)
+	}
+
+	d := &Device{name: name}
+	conf.Observer = &deviceObserver{manager: m, device: d, next: conf.Observer}
+	l, err := m.open(&conf)
+	if err != nil {
+		return nil, err
+	}
+	d.lora = l
+
+	if d.devEUI, err = readConfig(l, "dev_eui"); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to identify %s: %v", name, err)
+	}
+	if d.appEUI, err = readConfig(l, "app_eui"); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to identify %s: %v", name, err)
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		l.Close()
+		return nil, ErrDisconnected
+	}
+	if _, ok := m.devices[d.devEUI]; ok {
+		m.mu.Unlock()
+		l.Close()
+		return nil, fmt.Errorf("device %s already added", d.devEUI)
+	}
+	m.devices[d.devEUI] = d
+	m.names[name] = d.devEUI
+	m.mu.Unlock()
+
+	m.log.Info("device added", "dev_eui", d.devEUI, "app_eui", d.appEUI, "port", name)
+	m.emit(ManagerEvent{Type: DeviceAdded, DevEUI: d.devEUI})
+	return d, nil
+}
+
+// Remove closes the module.
+func (m *Manager) Remove(devEUI string) error {
+	if err := m.begin(); err != nil {
+		return err
+	}
+	defer m.ops.Done()
+	return m.remove(devEUI)
+}
+
+// begin counts an Add or Remove in progress, unless the manager is
+// closed.
+func (m *Manager) begin() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrDisconnected
+	}
+	m.ops.Add(1)
+	return nil
+}
+
+// remove closes the module, once no command is in progress.
+func (m *Manager) remove(devEUI string) error {
+	devEUI = strings.ToLower(devEUI)
+
+	m.mu.Lock()
+	d, ok := m.devices[devEUI]
+	if ok {
+		delete(m.devices, devEUI)
+		delete(m.names, d.name)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return ErrUnknownDevice
+	}
+
+	// wait for the command in progress
+	d.mu.Lock()
+	d.lora.Close()
+	d.mu.Unlock()
+
+	m.log.Info("device removed", "dev_eui", devEUI, "port", d.name)
+	m.emit(ManagerEvent{Type: DeviceRemoved, DevEUI: devEUI})
+	return nil
+}
+
+// Device returns the module with the DevEUI.
+func (m *Manager) Device(devEUI string) (*Device, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.devices[strings.ToLower(devEUI)]
+	return d, ok
+}
+
+// Devices returns the modules, sorted by DevEUI.
+func (m *Manager) Devices() []*Device {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	devices := make([]*Device, 0, len(m.devices))
+	for _, d := range m.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].devEUI < devices[j].devEUI
+	})
+	return devices
+}
+
+// Do runs fn with the module with the DevEUI, one call at a time.
+func (m *Manager) Do(devEUI string, fn func(l *Lora) error) error {
+	d, ok := m.Device(devEUI)
+	if !ok {
+		return ErrUnknownDevice
+	}
+	return d.Do(fn)
+}
+
+// Send sends the data, as for Lora.Send, with a module of the
+// application. The modules are used in turn, skipping the busy ones, and
+// the next one is tried when a module is disconnected, busy, not joined
+// or out of duty cycle. Returns the DevEUI of the module used.
+func (m *Manager) Send(appEUI, data string) (string, string, error) {
+	candidates := m.candidates(strings.ToLower(appEUI))
+	if len(candidates) == 0 {
+		return "", "", ErrNoDevice
+	}
+
+	// prefer the modules not running a command
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return !candidates[i].busy() && candidates[j].busy()
+	})
+
+	var err error
+	for _, d := range candidates {
+		var resp string
+		err = d.Do(func(l *Lora) error {
+			if l.State() != StateConnected {
+				return ErrDisconnected
+			}
+			var err error
+			resp, err = l.Send(data)
+			return err
+		})
+		if err == nil {
+			return d.devEUI, resp, nil
+		}
+		if !retryable(err) {
+			return d.devEUI, resp, err
+		}
+		m.log.Debug("send failed, trying the next device", "dev_eui", d.devEUI, "error", err)
+	}
+	return "", "", err
+}
+
+// Close closes every module and stops scanning.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.mu.Unlock()
+
+	if m.conf.Pattern != "" {
+		close(m.quit)
+	}
+	<-m.done
+	m.ops.Wait()
+
+	for _, d := range m.Devices() {
+		_ = m.remove(d.devEUI)
+	}
+
+	m.mu.Lock()
+	close(m.events)
+	m.mu.Unlock()
+}
+
+// candidates returns the modules of the application, in round robin
+// order.
+func (m *Manager) candidates(appEUI string) []*Device {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var devices []*Device
+	for _, d := range m.devices {
+		if d.appEUI == appEUI {
+			devices = append(devices, d)
+		}
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].devEUI < devices[j].devEUI
+	})
+
+	start := m.next[appEUI] % len(devices)
+	m.next[appEUI] = start + 1
+	return append(devices[start:], devices[:start]...)
+}
+
+// busy reports whether a command is running on the module.
+func (d *Device) busy() bool {
+	if d.mu.TryLock() {
+		d.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// retryable reports whether another module may succeed where err failed.
+func retryable(err error) bool {
+	if errors.Is(err, ErrDisconnected) || errors.Is(err, ErrDutyCycle) {
+		return true
+	}
+	code, ok := errorCode("", err)
+	return ok && (code == CodeMacBusyErr || code == CodeNotJoin)
+}
+
+// watch matches the pattern until the manager is closed.
+func (m *Manager) watch() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.conf.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		m.scan()
+		select {
+		case <-ticker.C:
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// scan adds the new devices matching the pattern and removes the ones
+// gone.
+func (m *Manager) scan() {
+	paths, err := m.glob(m.conf.Pattern)
+	if err != nil {
+		m.log.Error("invalid device pattern", "pattern", m.conf.Pattern, "error", err)
+		return
+	}
+	present := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		present[p] = true
+	}
+
+	m.mu.Lock()
+	var added []string
+	for _, p := range paths {
+		if _, ok := m.names[p]; !ok && !m.opening[p] {
+			m.opening[p] = true
+			added = append(added, p)
+		}
+	}
+	var removed []string
+	for name, devEUI := range m.names {
+		if !present[name] {
+			removed = append(removed, devEUI)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, devEUI := range removed {
+		_ = m.Remove(devEUI)
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range added {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, err := m.Add(name); err != nil {
+				m.log.Warn("failed to add device", "port", name, "error", err)
+			}
+			m.mu.Lock()
+			delete(m.opening, name)
+			m.mu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (m *Manager) emit(evt ManagerEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed && evt.Type == DeviceEvent {
+		return
+	}
+	select {
+	case m.events <- evt:
+	default:
+		m.log.Warn("manager event dropped", "type", evt.Type.String(), "dev_eui", evt.DevEUI)
+	}
+}
+
+// deviceObserver delivers the events of a module to the manager, then to
+// the observer configured.
+type deviceObserver struct {
+	manager *Manager
+	device  *Device
+	next    Observer
+}
+
+func (o *deviceObserver) ObserveCommand(name string, duration time.Duration, code int, err error) {
+	if o.next != nil {
+		o.next.ObserveCommand(name, duration, code, err)
+	}
+}
+
+func (o *deviceObserver) ObserveEvent(status int, frame *Frame) {
+	if devEUI := o.device.devEUI; devEUI != "" {
+		o.manager.emit(ManagerEvent{Type: DeviceEvent, DevEUI: devEUI, Status: status, Frame: frame})
+	}
+	if o.next != nil {
+		o.next.ObserveEvent(status, frame)
+	}
+}
+
+// readConfig reads a configuration value, in lower case.
+func readConfig(l *Lora, key string) (string, error) {
+	resp, err := l.GetConfig(key)
+	if err != nil {
+		return "", err
+	}
+	if err := isError(resp); err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimPrefix(resp, OK)), nil
+}
@@ -0,0 +1,350 @@
+package rak811
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+// fakeBus serves simulated modules as the devices of a Manager.
+type fakeBus struct {
+	t *testing.T
+
+	mu    sync.Mutex
+	addrs map[string]string // address by path
+}
+
+func newFakeBus(t *testing.T) *fakeBus {
+	return &fakeBus{t: t, addrs: make(map[string]string)}
+}
+
+// plug serves module at path.
+func (b *fakeBus) plug(path string, module *simulator.Module) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	go module.Serve(ln)
+	b.t.Cleanup(func() { ln.Close() })
+
+	b.mu.Lock()
+	b.addrs[path] = ln.Addr().String()
+	b.mu.Unlock()
+}
+
+func (b *fakeBus) unplug(path string) {
+	b.mu.Lock()
+	delete(b.addrs, path)
+	b.mu.Unlock()
+}
+
+func (b *fakeBus) glob(string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var paths []string
+	for p := range b.addrs {
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+func (b *fakeBus) open(conf *Config) (*Lora, error) {
+	b.mu.Lock()
+	addr, ok := b.addrs[conf.Name]
+	b.mu.Unlock()
+	if !ok {
+		return nil, errors.New("no such file or directory")
+	}
+	c := *conf
+	c.Name = "tcp://" + addr
+	return New(&c)
+}
+
+func newManagerModule(devEUI, appEUI string) *simulator.Module {
+	module := simulator.New()
+	module.SetConfig("dev_eui", devEUI)
+	module.SetConfig("app_eui", appEUI)
+	return module
+}
+
+func nextEvent(t *testing.T, events <-chan ManagerEvent, typ ManagerEventType) ManagerEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case evt := <-events:
+			if evt.Type == typ {
+				return evt
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func TestManager_Add(t *testing.T) {
+	bus := newFakeBus(t)
+	a := newManagerModule("AA00000000000001", "0011")
+	bus.plug("/dev/ttyUSB0", a)
+	bus.plug("/dev/ttyUSB1", newManagerModule("aa00000000000002", "0011"))
+
+	m := newManager(&ManagerConfig{Config: Config{Timeout: time.Second}}, bus.open, bus.glob)
+	defer m.Close()
+
+	d, err := m.Add("/dev/ttyUSB0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.DevEUI() != "aa00000000000001" || d.AppEUI() != "0011" || d.Name() != "/dev/ttyUSB0" {
+		t.Errorf("got %s, %s, %s", d.DevEUI(), d.AppEUI(), d.Name())
+	}
+	if evt := nextEvent(t, m.Events(), DeviceAdded); evt.DevEUI != "aa00000000000001" {
+		t.Errorf("got %+v", evt)
+	}
+	if _, err := m.Add("/dev/ttyUSB1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add("/dev/ttyUSB2"); err == nil {
+		t.Error("want error for a missing device")
+	}
+
+	devices := m.Devices()
+	if len(devices) != 2 || devices[0].DevEUI() != "aa00000000000001" {
+		t.Fatalf("got %d devices", len(devices))
+	}
+
+	err = m.Do("AA00000000000001", func(l *Lora) error {
+		_, err := l.Version()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmds := a.Commands(); cmds[len(cmds)-1] != "version" {
+		t.Errorf("got %q", cmds)
+	}
+	if err := m.Do("ff", func(*Lora) error { return nil }); err != ErrUnknownDevice {
+		t.Errorf("got %v", err)
+	}
+
+	// the events are routed by DevEUI
+	err = m.Do("aa00000000000002", func(l *Lora) error {
+		_, err := l.JoinOTAA()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evt := nextEvent(t, m.Events(), DeviceEvent); evt.DevEUI != "aa00000000000002" || evt.Status != StatusJoinedSuccess {
+		t.Errorf("got %+v", evt)
+	}
+
+	if err := m.Remove("aa00000000000001"); err != nil {
+		t.Fatal(err)
+	}
+	if evt := nextEvent(t, m.Events(), DeviceRemoved); evt.DevEUI != "aa00000000000001" {
+		t.Errorf("got %+v", evt)
+	}
+	if _, ok := m.Device("aa00000000000001"); ok {
+		t.Error("device not removed")
+	}
+	if err := m.Remove("aa00000000000001"); err != ErrUnknownDevice {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestManager_Duplicate(t *testing.T) {
+	bus := newFakeBus(t)
+	bus.plug("/dev/ttyUSB0", newManagerModule("aa00000000000001", "0011"))
+	bus.plug("/dev/ttyUSB1", newManagerModule("aa00000000000001", "0011"))
+
+	m := newManager(&ManagerConfig{Config: Config{Timeout: time.Second}}, bus.open, bus.glob)
+	defer m.Close()
+
+	if _, err := m.Add("/dev/ttyUSB0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add("/dev/ttyUSB1"); err == nil {
+		t.Error("want error for a duplicate DevEUI")
+	}
+}
+
+func TestManager_Configure(t *testing.T) {
+	bus := newFakeBus(t)
+	bus.plug("/dev/ttyUSB0", newManagerModule("aa00000000000001", "0011"))
+
+	shared := &ManagerConfig{Config: Config{Timeout: time.Second, DutyCycle: NewDutyCycle(DutyCycleConfig{})}}
+	m := newManager(shared, bus.open, bus.glob)
+	if _, err := m.Add("/dev/ttyUSB0"); !errors.Is(err, errSharedConfig) {
+		t.Errorf("got %v", err)
+	}
+	m.Close()
+
+	routers := make(map[string]*Router)
+	m = newManager(&ManagerConfig{
+		Config: Config{Timeout: time.Second},
+		Configure: func(name string, conf *Config) {
+			routers[name] = NewRouter()
+			conf.Router = routers[name]
+		},
+	}, bus.open, bus.glob)
+	defer m.Close()
+
+	d, err := m.Add("/dev/ttyUSB0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.lora.router == nil || d.lora.router != routers["/dev/ttyUSB0"] {
+		t.Error("router not configured")
+	}
+}
+
+func TestManager_CloseWhileRemoving(t *testing.T) {
+	bus := newFakeBus(t)
+	bus.plug("/dev/ttyUSB0", newManagerModule("aa00000000000001", "0011"))
+
+	m := newManager(&ManagerConfig{Config: Config{Timeout: time.Second}}, bus.open, bus.glob)
+	if _, err := m.Add("/dev/ttyUSB0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a command holds the device while Remove waits for it
+	release := make(chan struct{})
+	busy := make(chan struct{})
+	go m.Do("aa00000000000001", func(*Lora) error {
+		close(busy)
+		<-release
+		return nil
+	})
+	<-busy
+
+	removed := make(chan error, 1)
+	go func() { removed <- m.Remove("aa00000000000001") }()
+	for {
+		if _, ok := m.Device("aa00000000000001"); !ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Close waits for the Remove, whose event isn't sent on a closed
+	// channel
+	closed := make(chan struct{})
+	go func() {
+		m.Close()
+		close(closed)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if err := <-removed; err != nil {
+		t.Fatal(err)
+	}
+	<-closed
+	if evt := nextEvent(t, m.Events(), DeviceRemoved); evt.DevEUI != "aa00000000000001" {
+		t.Errorf("got %+v", evt)
+	}
+	if err := m.Remove("aa00000000000001"); err == nil {
+		t.Error("want error after Close")
+	}
+}
+
+func TestManager_Send(t *testing.T) {
+	bus := newFakeBus(t)
+	modules := []*simulator.Module{
+		newManagerModule("aa00000000000001", "0011"),
+		newManagerModule("aa00000000000002", "0011"),
+		newManagerModule("aa00000000000003", "2233"),
+	}
+	for i, module := range modules {
+		bus.plug("/dev/ttyUSB"+string(rune('0'+i)), module)
+	}
+
+	m := newManager(&ManagerConfig{Config: Config{Timeout: time.Second}}, bus.open, bus.glob)
+	defer m.Close()
+
+	for i := range modules {
+		if _, err := m.Add("/dev/ttyUSB" + string(rune('0'+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// not joined, every module of the application is tried
+	if _, _, err := m.Send("0011", "0,1,01"); err == nil {
+		t.Fatal("want error when no module is joined")
+	}
+	for _, module := range modules[:2] {
+		if cmds := module.Commands(); cmds[len(cmds)-1] != "send=0,1,01" {
+			t.Errorf("got %q", cmds)
+		}
+	}
+
+	for _, d := range m.Devices() {
+		err := d.Do(func(l *Lora) error {
+			_, err := l.JoinOTAA()
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		devEUI, _, err := m.Send("0011", "0,1,01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		used[devEUI]++
+	}
+	if used["aa00000000000001"] != 2 || used["aa00000000000002"] != 2 {
+		t.Errorf("got %v", used)
+	}
+
+	if devEUI, _, err := m.Send("2233", "0,1,01"); err != nil || devEUI != "aa00000000000003" {
+		t.Errorf("got %s, %v", devEUI, err)
+	}
+	if _, _, err := m.Send("4455", "0,1,01"); err != ErrNoDevice {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestManager_Scan(t *testing.T) {
+	bus := newFakeBus(t)
+	bus.plug("/dev/ttyUSB0", newManagerModule("aa00000000000001", "0011"))
+
+	m := newManager(&ManagerConfig{
+		Pattern:      "/dev/ttyUSB*",
+		ScanInterval: 10 * time.Millisecond,
+		Config:       Config{Timeout: time.Second},
+	}, bus.open, bus.glob)
+	defer m.Close()
+
+	if evt := nextEvent(t, m.Events(), DeviceAdded); evt.DevEUI != "aa00000000000001" {
+		t.Errorf("got %+v", evt)
+	}
+
+	bus.plug("/dev/ttyUSB1", newManagerModule("aa00000000000002", "0011"))
+	if evt := nextEvent(t, m.Events(), DeviceAdded); evt.DevEUI != "aa00000000000002" {
+		t.Errorf("got %+v", evt)
+	}
+
+	bus.unplug("/dev/ttyUSB0")
+	if evt := nextEvent(t, m.Events(), DeviceRemoved); evt.DevEUI != "aa00000000000001" {
+		t.Errorf("got %+v", evt)
+	}
+
+	m.Close()
+	if evt := nextEvent(t, m.Events(), DeviceRemoved); evt.DevEUI != "aa00000000000002" {
+		t.Errorf("got %+v", evt)
+	}
+	// the channel is closed after the last event
+	for range m.Events() {
+	}
+}
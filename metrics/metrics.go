@@ -0,0 +1,157 @@
+// Package metrics exports Prometheus metrics for the commands, uplinks,
+// joins, downlinks and module errors of a rak811.Lora.
+//
+// The Observer is set in the rak811.Config:
+//
+//	obs, err := metrics.New(prometheus.DefaultRegisterer)
+//	...
+//	lora, err := rak811.New(&rak811.Config{Name: "/dev/ttyUSB0", Observer: obs})
+//
+// Several modules can share a registry by wrapping it with a label
+// identifying them, see prometheus.WrapRegistererWith.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/calvernaz/rak811"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "rak811"
+
+// Command results.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Uplink results, from the event ending a send.
+const (
+	UplinkConfirmed   = "confirmed"
+	UplinkUnconfirmed = "unconfirmed"
+	UplinkTimeout     = "timeout"
+)
+
+// Join results, JoinError when the module refused the join command.
+const (
+	JoinSuccess = "success"
+	JoinFailed  = "failed"
+	JoinTimeout = "timeout"
+	JoinError   = "error"
+)
+
+// Observer rak811.Observer updating the metrics.
+type Observer struct {
+	commands        *prometheus.CounterVec
+	commandDuration *prometheus.HistogramVec
+	uplinks         *prometheus.CounterVec
+	joins           *prometheus.CounterVec
+	downlinks       prometheus.Counter
+	downlinkRSSI    prometheus.Histogram
+	downlinkSNR     prometheus.Histogram
+	errors          *prometheus.CounterVec
+}
+
+var _ rak811.Observer = (*Observer)(nil)
+
+// New creates the metrics and registers them with reg.
+func New(reg prometheus.Registerer) (*Observer, error) {
+	o := &Observer{
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "commands_total",
+			Help:      "AT commands sent to the module, by command and result.",
+		}, []string{"command", "result"}),
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "command_duration_seconds",
+			Help:      "Time the module took to answer an AT command, by command.",
+			Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"command"}),
+		uplinks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "uplinks_total",
+			Help:      "LoRaWAN uplinks, by result.",
+		}, []string{"result"}),
+		joins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "joins_total",
+			Help:      "LoRaWAN join attempts, by result.",
+		}, []string{"result"}),
+		downlinks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downlinks_total",
+			Help:      "Frames received from the network or P2P peers.",
+		}),
+		downlinkRSSI: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "downlink_rssi_dbm",
+			Help:      "RSSI of the frames received.",
+			Buckets:   prometheus.LinearBuckets(-130, 10, 11),
+		}),
+		downlinkSNR: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "downlink_snr_db",
+			Help:      "SNR of the frames received.",
+			Buckets:   prometheus.LinearBuckets(-20, 2.5, 17),
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "module_errors_total",
+			Help:      "Errors reported by the module, by LoraError code.",
+		}, []string{"code"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		o.commands, o.commandDuration, o.uplinks, o.joins,
+		o.downlinks, o.downlinkRSSI, o.downlinkSNR, o.errors,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// ObserveCommand implements rak811.Observer.
+func (o *Observer) ObserveCommand(name string, duration time.Duration, code int, err error) {
+	result := ResultOK
+	if err != nil || code != 0 {
+		result = ResultError
+	}
+	o.commands.WithLabelValues(name, result).Inc()
+	o.commandDuration.WithLabelValues(name).Observe(duration.Seconds())
+
+	if code != 0 {
+		o.errors.WithLabelValues(strconv.Itoa(code)).Inc()
+		if name == "join" {
+			o.joins.WithLabelValues(JoinError).Inc()
+		}
+	}
+}
+
+// ObserveEvent implements rak811.Observer.
+func (o *Observer) ObserveEvent(status int, frame *rak811.Frame) {
+	switch status {
+	case rak811.StatusRecvData:
+		o.downlinks.Inc()
+		if frame != nil && (frame.RSSI != 0 || frame.SNR != 0) {
+			o.downlinkRSSI.Observe(float64(frame.RSSI))
+			o.downlinkSNR.Observe(float64(frame.SNR))
+		}
+	case rak811.StatusTxConfirmed:
+		o.uplinks.WithLabelValues(UplinkConfirmed).Inc()
+	case rak811.StatusTxUnconfirmed:
+		o.uplinks.WithLabelValues(UplinkUnconfirmed).Inc()
+	case rak811.StatusTxTimeout:
+		o.uplinks.WithLabelValues(UplinkTimeout).Inc()
+	case rak811.StatusJoinedSuccess:
+		o.joins.WithLabelValues(JoinSuccess).Inc()
+	case rak811.StatusJoinedFailed:
+		o.joins.WithLabelValues(JoinFailed).Inc()
+	case rak811.StatusRx2Timeout:
+		o.joins.WithLabelValues(JoinTimeout).Inc()
+	}
+}
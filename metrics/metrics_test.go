@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+
+	"github.com/calvernaz/rak811"
+	"github.com/calvernaz/rak811/simulator"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newLora(t *testing.T, module *simulator.Module, obs rak811.Observer) *rak811.Lora {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go module.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	lora, err := rak811.New(&rak811.Config{Name: "tcp://" + ln.Addr().String(), Observer: obs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(lora.Close)
+	return lora
+}
+
+func TestObserver(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	obs, err := New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	module := simulator.New()
+	lora := newLora(t, module, obs)
+
+	if _, err := lora.Send("1,2,00"); err == nil {
+		t.Fatal("expected error, not joined")
+	}
+	if _, err := lora.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lora.Send("1,2,00"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lora.Send("0,2,00"); err != nil {
+		t.Fatal(err)
+	}
+	module.QueueDownlink(simulator.Downlink{Port: 3, RSSI: -72, SNR: 6, Payload: []byte{1}})
+	if _, err := lora.Send("0,2,00"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{"send ok", obs.commands.WithLabelValues("send", ResultOK), 3},
+		{"send error", obs.commands.WithLabelValues("send", ResultError), 1},
+		{"join ok", obs.commands.WithLabelValues("join", ResultOK), 1},
+		{"not joined", obs.errors.WithLabelValues("-5"), 1},
+		{"confirmed", obs.uplinks.WithLabelValues(UplinkConfirmed), 1},
+		{"unconfirmed", obs.uplinks.WithLabelValues(UplinkUnconfirmed), 1},
+		{"joined", obs.joins.WithLabelValues(JoinSuccess), 1},
+		{"downlinks", obs.downlinks, 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(tt.c); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if n := testutil.CollectAndCount(obs.commandDuration); n != 2 {
+		t.Errorf("got %d command duration series, want 2", n)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums := map[string]float64{"rak811_downlink_rssi_dbm": -72, "rak811_downlink_snr_db": 6}
+	for _, mf := range mfs {
+		want, ok := sums[mf.GetName()]
+		if !ok {
+			continue
+		}
+		h := mf.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 1 || h.GetSampleSum() != want {
+			t.Errorf("%s: got %v", mf.GetName(), h)
+		}
+	}
+}
+
+func TestNew_Registered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := New(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(reg); err == nil {
+		t.Error("expected error registering twice")
+	}
+}
@@ -0,0 +1,411 @@
+// Package mqttbridge connects a rak811.Lora to an MQTT broker. The topics
+// of a module start with <prefix>/<deveui>/:
+//
+//	state      online or offline, retained, offline is the will message
+//	join       result of the last join, retained
+//	tx         uplinks to send, subscribed
+//	tx/result  result of the uplinks sent
+//	rx         downlinks received
+//
+// Uplinks are JSON objects with a base64 payload:
+//
+//	{"id": "42", "port": 2, "confirmed": true, "payload": "AQI="}
+//
+// the id, optional, is copied to the result.
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvernaz/rak811"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Topics, relative to <prefix>/<deveui>.
+const (
+	TopicState    = "state"
+	TopicJoin     = "join"
+	TopicTx       = "tx"
+	TopicTxResult = "tx/result"
+	TopicRx       = "rx"
+)
+
+// States published to TopicState.
+const (
+	StateOnline  = "online"
+	StateOffline = "offline"
+)
+
+// Uplink statuses, see TxResult.
+const (
+	StatusConfirmed   = "confirmed"
+	StatusUnconfirmed = "unconfirmed"
+	StatusTimeout     = "timeout"
+	StatusError       = "error"
+)
+
+// Join results, see JoinState.
+const (
+	JoinSuccess = "success"
+	JoinFailed  = "failed"
+	JoinTimeout = "timeout"
+	JoinError   = "error"
+)
+
+const (
+	defaultPrefix  = "rak811"
+	defaultTimeout = 10 * time.Second
+
+	// txBacklog uplinks waiting to be sent, the others are refused.
+	txBacklog = 16
+
+	// subscriptionRefused SUBACK return code of a refused subscription.
+	subscriptionRefused = 0x80
+)
+
+// ErrBusy too many uplinks are waiting to be sent.
+var ErrBusy = errors.New("too many pending uplinks")
+
+// Config of a bridge.
+type Config struct {
+	// Prefix of the topics, defaults to rak811.
+	Prefix string
+	// DevEUI identifies the module in the topics, read from the module
+	// when empty.
+	DevEUI string
+	// QoS of the messages published and of the tx subscription.
+	QoS byte
+	// Timeout waiting for the broker to acknowledge a connection,
+	// subscription or message, defaults to 10s.
+	Timeout time.Duration
+	// Logger receives the errors, nothing is logged by default.
+	Logger *slog.Logger
+}
+
+// TxRequest uplink received on the tx topic.
+type TxRequest struct {
+	ID        string `json:"id,omitempty"`
+	Port      uint8  `json:"port"`
+	Confirmed bool   `json:"confirmed"`
+	Payload   []byte `json:"payload"`
+}
+
+// TxResult published to the tx/result topic, with the module error code
+// if any.
+type TxResult struct {
+	ID     string    `json:"id,omitempty"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	Code   int       `json:"code,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Downlink published to the rx topic.
+type Downlink struct {
+	Port    uint8     `json:"port"`
+	RSSI    int       `json:"rssi"`
+	SNR     int       `json:"snr"`
+	Payload []byte    `json:"payload"`
+	Time    time.Time `json:"time"`
+}
+
+// JoinState published to the join topic.
+type JoinState struct {
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Bridge publishes the activity of a module and sends the uplinks
+// received from the broker.
+type Bridge struct {
+	lora   *rak811.Lora
+	client mqtt.Client
+	conf   Config
+	base   string
+	log    *slog.Logger
+
+	// mu serializes the module commands.
+	mu sync.Mutex
+
+	// ready receives the outcome of the first connection handler, once
+	// subscribed and online.
+	ready     chan error
+	readyOnce sync.Once
+
+	txs  chan TxRequest
+	quit chan struct{}
+	done chan struct{}
+}
+
+// New connects to the broker with opts and returns once subscribed to the
+// uplinks. The will and connection handler of opts are replaced by the
+// bridge.
+func New(lora *rak811.Lora, opts *mqtt.ClientOptions, conf Config) (*Bridge, error) {
+	if conf.Prefix == "" {
+		conf.Prefix = defaultPrefix
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+	log := conf.Logger
+	if log == nil {
+		log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	if conf.DevEUI == "" {
+		resp, err := lora.GetConfig("dev_eui")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dev_eui: %v", err)
+		}
+		if lerr := rak811.WhichError(resp); lerr != nil {
+			return nil, fmt.Errorf("failed to read dev_eui: %v", resp)
+		}
+		conf.DevEUI = strings.ToLower(strings.TrimPrefix(resp, rak811.OK))
+	}
+
+	b := &Bridge{
+		lora:  lora,
+		conf:  conf,
+		base:  conf.Prefix + "/" + conf.DevEUI + "/",
+		log:   log.With("dev_eui", conf.DevEUI),
+		ready: make(chan error, 1),
+		txs:   make(chan TxRequest, txBacklog),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	opts.SetWill(b.Topic(TopicState), StateOffline, conf.QoS, true)
+	opts.SetOnConnectHandler(b.onConnect)
+
+	b.client = mqtt.NewClient(opts)
+	if err := b.wait(b.client.Connect()); err != nil {
+		return nil, fmt.Errorf("failed to connect to the broker: %v", err)
+	}
+	select {
+	case err := <-b.ready:
+		if err != nil {
+			b.client.Disconnect(0)
+			return nil, err
+		}
+	case <-time.After(b.conf.Timeout):
+		b.client.Disconnect(0)
+		return nil, errors.New("timeout waiting for the broker")
+	}
+
+	go b.run()
+	return b, nil
+}
+
+// Topic returns the full name of a topic of the module.
+func (b *Bridge) Topic(topic string) string {
+	return b.base + topic
+}
+
+// DevEUI returns the DevEUI identifying the module in the topics.
+func (b *Bridge) DevEUI() string {
+	return b.conf.DevEUI
+}
+
+// Join joins the network in OTAA mode and publishes the result.
+func (b *Bridge) Join() (string, error) {
+	b.mu.Lock()
+	resp, err := b.lora.JoinOTAA()
+	b.mu.Unlock()
+
+	state := JoinState{Time: time.Now().UTC()}
+	switch {
+	case err != nil:
+		state.Result, state.Error = JoinError, err.Error()
+	case resp == rak811.JoinSuccess:
+		state.Result = JoinSuccess
+	case resp == rak811.JoinFail:
+		state.Result = JoinFailed
+	case resp == rak811.JoinTimeout:
+		state.Result = JoinTimeout
+	default:
+		state.Result, state.Error = JoinError, resp
+	}
+
+	if perr := b.publishJSON(TopicJoin, true, state); perr != nil {
+		b.log.Warn("failed to publish join state", "error", perr)
+	}
+	return resp, err
+}
+
+// Close publishes the offline state and disconnects from the broker,
+// the uplinks still pending are dropped.
+func (b *Bridge) Close() error {
+	select {
+	case <-b.quit:
+		return nil
+	default:
+	}
+	close(b.quit)
+	<-b.done
+
+	err := b.publish(TopicState, true, []byte(StateOffline))
+	b.client.Disconnect(uint(b.conf.Timeout / time.Millisecond))
+	return err
+}
+
+// onConnect subscribes to the uplinks and publishes the online state,
+// after every connection to the broker.
+func (b *Bridge) onConnect(c mqtt.Client) {
+	err := b.subscribe(c)
+	if err != nil {
+		b.log.Error("failed to subscribe", "topic", b.Topic(TopicTx), "error", err)
+		err = fmt.Errorf("failed to subscribe to %s: %v", b.Topic(TopicTx), err)
+	} else if perr := b.publish(TopicState, true, []byte(StateOnline)); perr != nil {
+		b.log.Error("failed to publish state", "error", perr)
+	}
+	b.readyOnce.Do(func() { b.ready <- err })
+}
+
+// subscribe subscribes to the uplinks, a subscription refused by the
+// broker is an error.
+func (b *Bridge) subscribe(c mqtt.Client) error {
+	topic := b.Topic(TopicTx)
+	t := c.Subscribe(topic, b.conf.QoS, b.onTx)
+	if err := b.wait(t); err != nil {
+		return err
+	}
+	if st, ok := t.(*mqtt.SubscribeToken); ok && st.Result()[topic] == subscriptionRefused {
+		return errors.New("subscription refused")
+	}
+	return nil
+}
+
+// onTx queues an uplink. The handler must not block the client, errors
+// are published from another goroutine.
+func (b *Bridge) onTx(_ mqtt.Client, msg mqtt.Message) {
+	var req TxRequest
+	if err := json.Unmarshal(msg.Payload(), &req); err != nil {
+		go b.result(TxResult{Status: StatusError, Error: fmt.Sprintf("invalid uplink: %v", err)})
+		return
+	}
+
+	select {
+	case b.txs <- req:
+	default:
+		go b.result(TxResult{ID: req.ID, Status: StatusError, Error: ErrBusy.Error()})
+	}
+}
+
+// run sends the queued uplinks until the bridge is closed.
+func (b *Bridge) run() {
+	defer close(b.done)
+
+	for {
+		select {
+		case req := <-b.txs:
+			b.send(req)
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+// send sends an uplink and publishes its result, and the downlink
+// received if any.
+func (b *Bridge) send(req TxRequest) {
+	res := TxResult{ID: req.ID}
+	switch {
+	case req.Port < 1 || req.Port > 223:
+		res.Status, res.Error = StatusError, fmt.Sprintf("invalid port %d", req.Port)
+		b.result(res)
+		return
+	case len(req.Payload) == 0:
+		res.Status, res.Error = StatusError, rak811.ErrEmptyPayload.Error()
+		b.result(res)
+		return
+	}
+
+	confirmed := 0
+	if req.Confirmed {
+		confirmed = 1
+	}
+	b.mu.Lock()
+	resp, err := b.lora.Send(fmt.Sprintf("%d,%d,%X", confirmed, req.Port, req.Payload))
+	b.mu.Unlock()
+
+	if err != nil {
+		res.Status, res.Error = StatusError, err.Error()
+		res.Code, _ = rak811.ErrorCode(err)
+		b.result(res)
+		return
+	}
+
+	res.Status = StatusUnconfirmed
+	if req.Confirmed {
+		res.Status = StatusConfirmed
+	}
+
+	evt := rak811.WhichEventResponse(resp)
+	switch {
+	case evt == nil:
+		res.Status, res.Error = StatusError, fmt.Sprintf("unexpected response %q", resp)
+	case evt.Code() == rak811.StatusRecvData:
+		b.downlink(resp)
+	case evt.Code() == rak811.StatusTxTimeout:
+		res.Status = StatusTimeout
+	case evt.Code() != rak811.StatusTxConfirmed && evt.Code() != rak811.StatusTxUnconfirmed:
+		res.Status, res.Error = StatusError, evt.Description()
+	}
+	b.result(res)
+}
+
+// downlink publishes the frame of an at+recv=0 event.
+func (b *Bridge) downlink(resp string) {
+	frame, err := rak811.ParseFrame(resp)
+	if err != nil {
+		b.log.Warn("invalid downlink", "event", resp, "error", err)
+		return
+	}
+
+	d := Downlink{
+		Port:    frame.Port,
+		RSSI:    frame.RSSI,
+		SNR:     frame.SNR,
+		Payload: frame.Payload,
+		Time:    time.Now().UTC(),
+	}
+	if err := b.publishJSON(TopicRx, false, d); err != nil {
+		b.log.Warn("failed to publish downlink", "error", err)
+	}
+}
+
+func (b *Bridge) result(res TxResult) {
+	res.Time = time.Now().UTC()
+	if err := b.publishJSON(TopicTxResult, false, res); err != nil {
+		b.log.Warn("failed to publish uplink result", "id", res.ID, "error", err)
+	}
+}
+
+func (b *Bridge) publishJSON(topic string, retained bool, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.publish(topic, retained, payload)
+}
+
+func (b *Bridge) publish(topic string, retained bool, payload []byte) error {
+	return b.wait(b.client.Publish(b.Topic(topic), b.conf.QoS, retained, payload))
+}
+
+// wait waits for the broker to acknowledge, QoS 0 messages are
+// acknowledged once written.
+func (b *Bridge) wait(t mqtt.Token) error {
+	if !t.WaitTimeout(b.conf.Timeout) {
+		return errors.New("timeout waiting for the broker")
+	}
+	return t.Error()
+}
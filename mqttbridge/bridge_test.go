@@ -0,0 +1,228 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811"
+	"github.com/calvernaz/rak811/simulator"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	server "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// newBroker starts an embedded broker and returns its address.
+func newBroker(t *testing.T) string {
+	t.Helper()
+	return newBrokerWithHook(t, new(auth.AllowHook))
+}
+
+// denyTxHook refuses the subscriptions to the uplinks.
+type denyTxHook struct {
+	auth.AllowHook
+}
+
+func (h *denyTxHook) OnACLCheck(cl *server.Client, topic string, write bool) bool {
+	return write || !strings.HasSuffix(topic, "/"+TopicTx)
+}
+
+// newBrokerWithHook starts an embedded broker authorizing the clients
+// with hook and returns its address.
+func newBrokerWithHook(t *testing.T, hook server.Hook) string {
+	t.Helper()
+
+	s := server.New(&server.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := s.AddHook(hook, nil); err != nil {
+		t.Fatal(err)
+	}
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	if err := s.AddListener(tcp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Serve(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return "tcp://" + tcp.Address()
+}
+
+func newLora(t *testing.T, module *simulator.Module) *rak811.Lora {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go module.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	lora, err := rak811.New(&rak811.Config{Name: "tcp://" + ln.Addr().String(), Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(lora.Close)
+	return lora
+}
+
+type message struct {
+	topic    string
+	payload  []byte
+	retained bool
+}
+
+// subscribe returns the messages published on the topics of the module.
+func subscribe(t *testing.T, broker, id string) <-chan message {
+	t.Helper()
+
+	msgs := make(chan message, 16)
+	c := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID(id))
+	if tok := c.Connect(); !tok.WaitTimeout(5*time.Second) || tok.Error() != nil {
+		t.Fatalf("connect: %v", tok.Error())
+	}
+	t.Cleanup(func() { c.Disconnect(100) })
+
+	tok := c.Subscribe("rak811/#", 1, func(_ mqtt.Client, m mqtt.Message) {
+		msgs <- message{m.Topic(), m.Payload(), m.Retained()}
+	})
+	if !tok.WaitTimeout(5*time.Second) || tok.Error() != nil {
+		t.Fatalf("subscribe: %v", tok.Error())
+	}
+	return msgs
+}
+
+// next returns the next message published on topic.
+func next(t *testing.T, msgs <-chan message, topic string) message {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case m := <-msgs:
+			if m.topic == topic {
+				return m
+			}
+		case <-timeout:
+			t.Fatalf("no message on %s", topic)
+		}
+	}
+}
+
+func TestBridge(t *testing.T) {
+	broker := newBroker(t)
+	module := simulator.New()
+	lora := newLora(t, module)
+
+	b, err := New(lora, mqtt.NewClientOptions().AddBroker(broker).SetClientID("bridge"), Config{QoS: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if b.DevEUI() != "0102030405060708" {
+		t.Fatalf("got dev eui %q", b.DevEUI())
+	}
+
+	msgs := subscribe(t, broker, "app")
+	if m := next(t, msgs, "rak811/0102030405060708/state"); string(m.payload) != StateOnline || !m.retained {
+		t.Errorf("got state %q, retained %v", m.payload, m.retained)
+	}
+
+	if _, err := b.Join(); err != nil {
+		t.Fatal(err)
+	}
+	var join JoinState
+	m := next(t, msgs, b.Topic(TopicJoin))
+	if err := json.Unmarshal(m.payload, &join); err != nil || join.Result != JoinSuccess {
+		t.Errorf("got join %s: %v", m.payload, err)
+	}
+
+	module.QueueDownlink(simulator.Downlink{Port: 4, RSSI: -90, SNR: 3, Payload: []byte{0xbe, 0xef}})
+	pub := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID("pub"))
+	if tok := pub.Connect(); !tok.WaitTimeout(5*time.Second) || tok.Error() != nil {
+		t.Fatalf("connect: %v", tok.Error())
+	}
+	defer pub.Disconnect(100)
+	pub.Publish(b.Topic(TopicTx), 1, false, `{"id": "1", "port": 2, "confirmed": true, "payload": "AQI="}`).Wait()
+
+	var d Downlink
+	m = next(t, msgs, b.Topic(TopicRx))
+	if err := json.Unmarshal(m.payload, &d); err != nil || d.Port != 4 || d.RSSI != -90 || string(d.Payload) != "\xbe\xef" {
+		t.Errorf("got downlink %s: %v", m.payload, err)
+	}
+	var res TxResult
+	m = next(t, msgs, b.Topic(TopicTxResult))
+	if err := json.Unmarshal(m.payload, &res); err != nil || res.ID != "1" || res.Status != StatusConfirmed {
+		t.Errorf("got result %s: %v", m.payload, err)
+	}
+	cmds := module.Commands()
+	if last := cmds[len(cmds)-1]; last != "send=1,2,0102" {
+		t.Errorf("got %q", last)
+	}
+
+	pub.Publish(b.Topic(TopicTx), 1, false, `{"id": "2", "port": 0, "payload": "AQI="}`).Wait()
+	m = next(t, msgs, b.Topic(TopicTxResult))
+	if err := json.Unmarshal(m.payload, &res); err != nil || res.ID != "2" || res.Status != StatusError {
+		t.Errorf("got result %s: %v", m.payload, err)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m := next(t, msgs, b.Topic(TopicState)); string(m.payload) != StateOffline {
+		t.Errorf("got state %q", m.payload)
+	}
+
+	// the state is retained for the clients subscribing later
+	late := subscribe(t, broker, "late")
+	if m := next(t, late, b.Topic(TopicState)); string(m.payload) != StateOffline || !m.retained {
+		t.Errorf("got state %q, retained %v", m.payload, m.retained)
+	}
+}
+
+func TestBridge_NotJoined(t *testing.T) {
+	broker := newBroker(t)
+	lora := newLora(t, simulator.New())
+
+	b, err := New(lora, mqtt.NewClientOptions().AddBroker(broker).SetClientID("bridge"), Config{Prefix: "home", DevEUI: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	msgs := make(chan mqtt.Message, 1)
+	c := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID("app"))
+	if tok := c.Connect(); !tok.WaitTimeout(5*time.Second) || tok.Error() != nil {
+		t.Fatalf("connect: %v", tok.Error())
+	}
+	defer c.Disconnect(100)
+	c.Subscribe("home/abc/tx/result", 0, func(_ mqtt.Client, m mqtt.Message) { msgs <- m }).Wait()
+	c.Publish("home/abc/tx", 0, false, `{"port": 2, "payload": "AQI="}`).Wait()
+
+	select {
+	case m := <-msgs:
+		var res TxResult
+		if err := json.Unmarshal(m.Payload(), &res); err != nil || res.Status != StatusError || res.Code != rak811.CodeNotJoin {
+			t.Errorf("got result %s: %v", m.Payload(), err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result")
+	}
+}
+
+func TestBridge_SubscribeRefused(t *testing.T) {
+	broker := newBrokerWithHook(t, new(denyTxHook))
+	lora := newLora(t, simulator.New())
+
+	_, err := New(lora, mqtt.NewClientOptions().AddBroker(broker).SetClientID("bridge"), Config{QoS: 1, DevEUI: "0102030405060708"})
+	if err == nil || !strings.Contains(err.Error(), "subscription refused") {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package rak811
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// NodeHeaderSize bytes prepended to every frame: destination and source.
+	NodeHeaderSize = 2
+
+	// Broadcast destination address received by every node.
+	Broadcast NodeID = 0xff
+)
+
+// NodeID address of a node sharing the channel.
+type NodeID uint8
+
+// Datagram frame received by a node with its addresses.
+type Datagram struct {
+	Src NodeID
+	Dst NodeID
+	Frame
+}
+
+// PeerStats statistics of the frames received from a node.
+type PeerStats struct {
+	Frames   int
+	Bytes    int
+	RSSI     int
+	SNR      int
+	LastSeen time.Time
+}
+
+// Node addressed endpoint on a shared link. Frames addressed to other
+// nodes are discarded; frames addressed to the node or broadcast are
+// delivered on Receive, or on the link returned by Peer for the sender.
+type Node struct {
+	link Link
+	id   NodeID
+
+	datagrams chan Datagram
+
+	mu    sync.Mutex
+	peers map[NodeID]*peerLink
+	stats map[NodeID]*PeerStats
+}
+
+// NewNode starts receiving the frames addressed to id on link.
+func NewNode(link Link, id NodeID) (*Node, error) {
+	if id == Broadcast {
+		return nil, fmt.Errorf("invalid node id: %d", id)
+	}
+
+	n := &Node{
+		link:      link,
+		id:        id,
+		datagrams: make(chan Datagram, frameBacklog),
+		peers:     make(map[NodeID]*peerLink),
+		stats:     make(map[NodeID]*PeerStats),
+	}
+	go n.run()
+	return n, nil
+}
+
+// ID returns the node address.
+func (n *Node) ID() NodeID {
+	return n.id
+}
+
+// SendTo sends the payload to dst, use Broadcast to reach every node.
+func (n *Node) SendTo(ctx context.Context, dst NodeID, payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyPayload
+	}
+	return n.link.Transmit(ctx, append([]byte{byte(dst), byte(n.id)}, payload...))
+}
+
+// Receive returns the channel where the datagrams for the node are
+// delivered, except those from nodes with a Peer link. The channel is
+// closed when the underlying link receive channel is closed.
+func (n *Node) Receive() <-chan Datagram {
+	return n.datagrams
+}
+
+// Peer returns a link to a single node. It receives the unicast frames
+// from that node, so it can be used with Reliable or Fragmenter.
+func (n *Node) Peer(id NodeID) Link {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if peer, ok := n.peers[id]; ok {
+		return peer
+	}
+	peer := &peerLink{
+		node:   n,
+		id:     id,
+		frames: make(chan Frame, frameBacklog),
+	}
+	if n.peers == nil {
+		// the underlying link is closed
+		close(peer.frames)
+		return peer
+	}
+	n.peers[id] = peer
+	return peer
+}
+
+// Stats returns the statistics of the frames received from id.
+func (n *Node) Stats(id NodeID) PeerStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if stats, ok := n.stats[id]; ok {
+		return *stats
+	}
+	return PeerStats{}
+}
+
+// Peers returns the statistics of every node heard so far.
+func (n *Node) Peers() map[NodeID]PeerStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	peers := make(map[NodeID]PeerStats, len(n.stats))
+	for id, stats := range n.stats {
+		peers[id] = *stats
+	}
+	return peers
+}
+
+func (n *Node) run() {
+	defer func() {
+		n.mu.Lock()
+		for _, peer := range n.peers {
+			close(peer.frames)
+		}
+		n.peers = nil
+		n.mu.Unlock()
+		close(n.datagrams)
+	}()
+
+	for frame := range n.link.Receive() {
+		if len(frame.Payload) < NodeHeaderSize {
+			continue
+		}
+
+		dst, src := NodeID(frame.Payload[0]), NodeID(frame.Payload[1])
+		if src == n.id || (dst != n.id && dst != Broadcast) {
+			continue
+		}
+		frame.Payload = frame.Payload[NodeHeaderSize:]
+
+		n.mu.Lock()
+		stats, ok := n.stats[src]
+		if !ok {
+			stats = &PeerStats{}
+			n.stats[src] = stats
+		}
+		stats.Frames++
+		stats.Bytes += len(frame.Payload)
+		stats.RSSI = frame.RSSI
+		stats.SNR = frame.SNR
+		stats.LastSeen = time.Now()
+		peer := n.peers[src]
+		n.mu.Unlock()
+
+		if peer != nil && dst == n.id {
+			select {
+			case peer.frames <- frame:
+			default:
+			}
+			continue
+		}
+
+		select {
+		case n.datagrams <- Datagram{Src: src, Dst: dst, Frame: frame}:
+		default:
+		}
+	}
+}
+
+// peerLink link to a single node through its Node.
+type peerLink struct {
+	node   *Node
+	id     NodeID
+	frames chan Frame
+}
+
+func (p *peerLink) Transmit(ctx context.Context, payload []byte) error {
+	return p.node.SendTo(ctx, p.id, payload)
+}
+
+func (p *peerLink) Receive() <-chan Frame {
+	return p.frames
+}
@@ -0,0 +1,83 @@
+package rak811
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNode_SendTo(t *testing.T) {
+	medium := newMemMedium(nil)
+	a, _ := NewNode(medium.Link(), 1)
+	b, _ := NewNode(medium.Link(), 2)
+	c, _ := NewNode(medium.Link(), 3)
+
+	ctx := context.Background()
+	if err := a.SendTo(ctx, 2, []byte("unicast")); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if err := a.SendTo(ctx, Broadcast, []byte("broadcast")); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	for _, want := range []Datagram{
+		{Src: 1, Dst: 2, Frame: Frame{Payload: []byte("unicast")}},
+		{Src: 1, Dst: Broadcast, Frame: Frame{Payload: []byte("broadcast")}},
+	} {
+		d := receiveDatagram(t, b)
+		if d.Src != want.Src || d.Dst != want.Dst || string(d.Payload) != string(want.Payload) {
+			t.Errorf("got %+v, want %+v", d, want)
+		}
+	}
+
+	// node 3 only hears the broadcast
+	if d := receiveDatagram(t, c); d.Dst != Broadcast {
+		t.Errorf("got %+v, want broadcast", d)
+	}
+	select {
+	case d := <-c.Receive():
+		t.Errorf("got unexpected %+v", d)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if stats := b.Stats(1); stats.Frames != 2 || stats.Bytes != 16 {
+		t.Errorf("got %+v", stats)
+	}
+	if _, ok := c.Peers()[1]; !ok {
+		t.Error("want stats for node 1")
+	}
+}
+
+func TestNode_Peer(t *testing.T) {
+	medium := newMemMedium(nil)
+	a, _ := NewNode(medium.Link(), 1)
+	b, _ := NewNode(medium.Link(), 2)
+
+	config := ReliableConfig{Timeout: 20 * time.Millisecond}
+	ab := NewReliable(a.Peer(2), config)
+	ba := NewReliable(b.Peer(1), config)
+
+	if err := ab.Transmit(context.Background(), []byte("ping")); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	select {
+	case frame := <-ba.Receive():
+		if string(frame.Payload) != "ping" {
+			t.Errorf("got %q, want %q", frame.Payload, "ping")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+}
+
+func receiveDatagram(t *testing.T, n *Node) Datagram {
+	t.Helper()
+	select {
+	case d := <-n.Receive():
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for datagram")
+	}
+	return Datagram{}
+}
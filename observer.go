@@ -0,0 +1,55 @@
+package rak811
+
+import (
+	"strings"
+	"time"
+)
+
+// Observer is notified of the command exchanges and events of a Lora, e.g.
+// to export metrics, see the metrics package. The methods may be called
+// concurrently, from the goroutines talking to the module, and must not
+// block.
+type Observer interface {
+	// ObserveCommand called after every command exchange with the command
+	// name, e.g. "send", and the module error code, zero if it didn't
+	// report one.
+	ObserveCommand(name string, duration time.Duration, code int, err error)
+	// ObserveEvent called with every at+recv event reported by the module,
+	// frame is set for the data received.
+	ObserveEvent(status int, frame *Frame)
+}
+
+// commandName returns the name of a command, the text before the = sign.
+func commandName(cmd string) string {
+	if i := strings.IndexByte(cmd, '='); i >= 0 {
+		return cmd[:i]
+	}
+	return cmd
+}
+
+// observeCommand reports the outcome of a command exchange to the observer.
+func (l *Lora) observeCommand(cmd, resp string, err error, duration time.Duration) {
+	if l.observer == nil {
+		return
+	}
+	code, _ := errorCode(resp, err)
+	l.observer.ObserveCommand(commandName(cmd), duration, code, err)
+}
+
+// observeEvent reports an at+recv event to the observer.
+func (l *Lora) observeEvent(line string) {
+	if l.observer == nil {
+		return
+	}
+	evt := WhichEventResponse(line)
+	if evt == nil {
+		return
+	}
+
+	var frame *Frame
+	if evt.Code() == StatusRecvData {
+		// an invalid frame is still reported as an event
+		frame, _ = ParseFrame(line)
+	}
+	l.observer.ObserveEvent(evt.Code(), frame)
+}
@@ -0,0 +1,72 @@
+package rak811
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type observedCommand struct {
+	name string
+	code int
+	err  error
+}
+
+type observedEvent struct {
+	status int
+	frame  *Frame
+}
+
+// recordingObserver keeps everything it observes.
+type recordingObserver struct {
+	mu       sync.Mutex
+	commands []observedCommand
+	events   []observedEvent
+}
+
+func (o *recordingObserver) ObserveCommand(name string, _ time.Duration, code int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.commands = append(o.commands, observedCommand{name, code, err})
+}
+
+func (o *recordingObserver) ObserveEvent(status int, frame *Frame) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, observedEvent{status, frame})
+}
+
+func TestLora_Observer(t *testing.T) {
+	fsp := newFakeSerialConn(
+		[]byte("OK"+CrLf), []byte("at+recv=0,2,-60,7,2:CAFE"+CrLf),
+		[]byte("ERROR-5"+CrLf),
+	)
+	lora, _ := newLora(fsp)
+	obs := &recordingObserver{}
+	lora.observer = obs
+
+	if _, err := lora.Send("1,2,00"); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := lora.Send("1,2,00"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(obs.commands) != 2 {
+		t.Fatalf("got %d commands, want 2", len(obs.commands))
+	}
+	if c := obs.commands[0]; c.name != "send" || c.code != 0 || c.err != nil {
+		t.Errorf("got %+v", c)
+	}
+	if c := obs.commands[1]; c.name != "send" || c.code != CodeNotJoin || c.err == nil {
+		t.Errorf("got %+v", c)
+	}
+
+	if len(obs.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(obs.events))
+	}
+	evt := obs.events[0]
+	if evt.status != StatusRecvData || evt.frame == nil || evt.frame.RSSI != -60 || evt.frame.SNR != 7 {
+		t.Errorf("got %+v", evt)
+	}
+}
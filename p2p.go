@@ -0,0 +1,487 @@
+package rak811
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// ModeLoRaWAN module mode for LoRaWAN operation.
+	ModeLoRaWAN = 0
+	// ModeP2P module mode for LoraP2P operation.
+	ModeP2P = 1
+
+	// MaxP2PPayload maximum payload size, in bytes, of a LoraP2P frame.
+	MaxP2PPayload = 255
+
+	// frameBacklog number of received frames buffered before dropping.
+	frameBacklog = 16
+)
+
+var (
+	// ErrPayloadTooLarge the payload doesn't fit in a single frame.
+	ErrPayloadTooLarge = errors.New("payload exceeds the maximum frame size")
+	// ErrEmptyPayload the payload has no data.
+	ErrEmptyPayload = errors.New("payload is empty")
+	// ErrClosed the P2P link has been closed.
+	ErrClosed = errors.New("p2p link is closed")
+)
+
+// Bandwidth LoRa signal bandwidth as understood by rf_config.
+type Bandwidth int
+
+const (
+	BW125 Bandwidth = 0
+	BW250 Bandwidth = 1
+	BW500 Bandwidth = 2
+)
+
+// Hz returns the bandwidth in hertz.
+func (b Bandwidth) Hz() int {
+	switch b {
+	case BW125:
+		return 125000
+	case BW250:
+		return 250000
+	case BW500:
+		return 500000
+	}
+	return 0
+}
+
+// CodingRate LoRa forward error correction rate as understood by rf_config.
+type CodingRate int
+
+const (
+	CR4_5 CodingRate = 1
+	CR4_6 CodingRate = 2
+	CR4_7 CodingRate = 3
+	CR4_8 CodingRate = 4
+)
+
+// RFConfig LoraP2P radio parameters.
+type RFConfig struct {
+	// Frequency in hertz.
+	Frequency int
+	// SpreadingFactor 6 to 12.
+	SpreadingFactor int
+	Bandwidth       Bandwidth
+	CodingRate      CodingRate
+	// Preamble length in symbols, 8 to 65535.
+	Preamble int
+	// Power TX power in dBm, 5 to 20.
+	Power int
+}
+
+// DefaultRFConfig module defaults for the EU868 band.
+var DefaultRFConfig = RFConfig{
+	Frequency:       868100000,
+	SpreadingFactor: 12,
+	Bandwidth:       BW125,
+	CodingRate:      CR4_5,
+	Preamble:        8,
+	Power:           20,
+}
+
+// String formats the parameters as expected by at+rf_config.
+func (c RFConfig) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d,%d,%d",
+		c.Frequency, c.SpreadingFactor, c.Bandwidth, c.CodingRate, c.Preamble, c.Power)
+}
+
+// Validate checks the parameters are within the module ranges.
+func (c RFConfig) Validate() error {
+	switch {
+	case c.Frequency <= 0:
+		return fmt.Errorf("invalid frequency: %d", c.Frequency)
+	case c.SpreadingFactor < 6 || c.SpreadingFactor > 12:
+		return fmt.Errorf("invalid spreading factor: %d", c.SpreadingFactor)
+	case c.Bandwidth.Hz() == 0:
+		return fmt.Errorf("invalid bandwidth: %d", c.Bandwidth)
+	case c.CodingRate < CR4_5 || c.CodingRate > CR4_8:
+		return fmt.Errorf("invalid coding rate: %d", c.CodingRate)
+	case c.Preamble < 8 || c.Preamble > 65535:
+		return fmt.Errorf("invalid preamble length: %d", c.Preamble)
+	case c.Power < 5 || c.Power > 20:
+		return fmt.Errorf("invalid tx power: %d", c.Power)
+	}
+	return nil
+}
+
+// ParseRFConfig parses the at+rf_config response, with or without
+// the OK prefix.
+func ParseRFConfig(resp string) (RFConfig, error) {
+	fields := strings.Split(strings.TrimPrefix(resp, OK), ",")
+	if len(fields) != 6 {
+		return RFConfig{}, fmt.Errorf("invalid rf config: %q", resp)
+	}
+
+	values := make([]int, len(fields))
+	for i, f := range fields {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return RFConfig{}, fmt.Errorf("invalid rf config %q: %v", resp, err)
+		}
+		values[i] = v
+	}
+
+	return RFConfig{
+		Frequency:       values[0],
+		SpreadingFactor: values[1],
+		Bandwidth:       Bandwidth(values[2]),
+		CodingRate:      CodingRate(values[3]),
+		Preamble:        values[4],
+		Power:           values[5],
+	}, nil
+}
+
+// Frame data received from the server or a LoraP2P peer.
+type Frame struct {
+	Port    uint8
+	RSSI    int
+	SNR     int
+	Payload []byte
+}
+
+// ParseFrame parses an at+recv=0 event. RSSI and SNR are only present
+// when the module reports them, otherwise they are zero.
+// Format: at+recv=0,<port>[,<rssi>,<snr>],<len>[:<data>]
+func ParseFrame(resp string) (*Frame, error) {
+	if !strings.HasPrefix(resp, eventRespPrefix) {
+		return nil, fmt.Errorf("not an event response: %q", resp)
+	}
+
+	evt := strings.TrimPrefix(resp, eventRespPrefix)
+	var data string
+	if i := strings.IndexByte(evt, ':'); i >= 0 {
+		evt, data = evt[:i], evt[i+1:]
+	}
+
+	fields := strings.Split(evt, ",")
+	if len(fields) != 3 && len(fields) != 5 {
+		return nil, fmt.Errorf("invalid frame: %q", resp)
+	}
+
+	values := make([]int, len(fields))
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frame %q: %v", resp, err)
+		}
+		values[i] = v
+	}
+
+	if values[0] != StatusRecvData {
+		return nil, fmt.Errorf("not a data frame: %q", resp)
+	}
+	if values[1] < 0 || values[1] > 255 {
+		return nil, fmt.Errorf("invalid frame port: %q", resp)
+	}
+
+	frame := &Frame{Port: uint8(values[1])}
+	if len(values) == 5 {
+		frame.RSSI = values[2]
+		frame.SNR = values[3]
+	}
+
+	payload, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frame payload %q: %v", resp, err)
+	}
+	if len(payload) != values[len(values)-1] {
+		return nil, fmt.Errorf("frame length mismatch: %q", resp)
+	}
+	frame.Payload = payload
+
+	return frame, nil
+}
+
+// P2P LoraP2P link on top of a Lora module.
+//
+// While the link is open it owns the serial port: the module is kept in
+// receive mode and every frame it reports is delivered on Receive. The
+// Lora commands must not be used until the link is closed.
+type P2P struct {
+	lora *Lora
+
+	cmu    sync.Mutex // guards config, apart from the slow commands
+	config RFConfig
+
+	mu     sync.Mutex // serialises the commands sent to the module
+	frames chan Frame
+	resp   chan string
+	quit   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// NewP2P switches the module into LoraP2P mode, applies the RF config
+// and starts listening for frames.
+func NewP2P(l *Lora, config RFConfig) (*P2P, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if l.backgroundReader() != nil {
+		return nil, ErrListening
+	}
+
+	if _, err := l.SetMode(ModeP2P); err != nil {
+		return nil, fmt.Errorf("failed to set p2p mode: %v", err)
+	}
+	if _, err := l.SetRfConfig(config.String()); err != nil {
+		return nil, fmt.Errorf("failed to set rf config: %v", err)
+	}
+	if _, err := l.Rxc(1); err != nil {
+		return nil, fmt.Errorf("failed to enable receive mode: %v", err)
+	}
+
+	p := &P2P{
+		lora:   l,
+		config: config,
+		frames: make(chan Frame, frameBacklog),
+		resp:   make(chan string, frameBacklog),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go p.listen()
+
+	return p, nil
+}
+
+// Config returns the RF config of the link.
+func (p *P2P) Config() RFConfig {
+	p.cmu.Lock()
+	defer p.cmu.Unlock()
+	return p.config
+}
+
+// SetConfig applies the RF config to the link, stopping the receive mode
+// while the module is reconfigured.
+func (p *P2P) SetConfig(ctx context.Context, config RFConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.command(ctx, "rx_stop"); err != nil {
+		return err
+	}
+	if err := p.command(ctx, fmt.Sprintf("rf_config=%s", config)); err != nil {
+		return err
+	}
+
+	p.cmu.Lock()
+	p.config = config
+	p.cmu.Unlock()
+	p.lora.setRFConfig(&config)
+
+	return p.command(ctx, "rxc=1")
+}
+
+// Receive returns the channel where received frames are delivered.
+// Frames are dropped if the channel isn't drained. The channel is
+// closed when the link is closed.
+func (p *P2P) Receive() <-chan Frame {
+	return p.frames
+}
+
+// Transmit sends the payload in a single frame and puts the module back
+// into receive mode once the transmission has completed. The airtime is
+// accounted first when the Lora has a DutyCycle.
+func (p *P2P) Transmit(ctx context.Context, payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyPayload
+	}
+	if len(payload) > MaxP2PPayload {
+		return ErrPayloadTooLarge
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	release := func() {}
+	if dc := p.lora.dutyCycle; dc != nil {
+		config := p.Config()
+		var err error
+		release, err = dc.acquire(ctx.Done(), dc.region.Plan().subBands(config.Frequency), config.TimeOnAir(len(payload)))
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
+
+	if err := p.command(ctx, fmt.Sprintf("txc=1,0,%X", payload)); err != nil {
+		release()
+		return err
+	}
+	if err := p.await(ctx, StatusP2pComplete); err != nil {
+		return err
+	}
+	return p.command(ctx, "rxc=1")
+}
+
+// Close stops the receive mode and releases the serial port back to
+// the Lora commands.
+func (p *P2P) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	close(p.quit)
+	err := p.command(context.Background(), "rx_stop")
+	<-p.done
+
+	return err
+}
+
+// command sends the command through the Lora, as the other commands, and
+// waits for the module to acknowledge it.
+func (p *P2P) command(ctx context.Context, cmd string) error {
+	p.drain()
+
+	resp, err := p.lora.tx(cmd, func(*Lora) (string, error) {
+		return p.reply(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	if err := isError(resp); err != nil {
+		if lerr := WhichError(resp); lerr != nil {
+			return lerr
+		}
+		return err
+	}
+	return nil
+}
+
+// reply waits for the OK or ERROR reply of the command in progress.
+func (p *P2P) reply(ctx context.Context) (string, error) {
+	for {
+		select {
+		case resp, ok := <-p.resp:
+			if !ok {
+				return "", ErrClosed
+			}
+			if isOk(resp) || isError(resp) != nil {
+				return resp, nil
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// await waits for the module to report the event status.
+func (p *P2P) await(ctx context.Context, status int) error {
+	for {
+		select {
+		case resp, ok := <-p.resp:
+			if !ok {
+				return ErrClosed
+			}
+			if evt := WhichEventResponse(resp); evt != nil && evt.Code() == status {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// drain discards stale responses left by a cancelled command.
+func (p *P2P) drain() {
+	for {
+		select {
+		case <-p.resp:
+		default:
+			return
+		}
+	}
+}
+
+// listen reads the port until the link is closed, delivering frames to
+// the receive channel and everything else to the pending command.
+func (p *P2P) listen() {
+	defer close(p.done)
+	defer close(p.frames)
+	defer close(p.resp)
+
+	_, lines := p.lora.conn()
+	for {
+		line, err := lines.next()
+		if err != nil {
+			// serial timeout has triggered
+			if err == io.EOF {
+				select {
+				case <-p.quit:
+					return
+				default:
+				}
+				continue
+			}
+			if err == errLineTooLong {
+				p.lora.log.Warn("dropped over-long line")
+				continue
+			}
+			p.lora.log.Error("p2p read failed", "error", err)
+			p.lora.lost(&portError{fmt.Errorf("failed read: %v", err)})
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		p.lora.log.Debug("p2p reply", "reply", line)
+		p.lora.observeEvent(line)
+
+		if evt := WhichEventResponse(line); evt != nil && evt.Code() == StatusRecvData {
+			frame, err := ParseFrame(line)
+			if err != nil {
+				p.lora.log.Warn("invalid p2p frame", "event", line, "error", err)
+				continue
+			}
+			select {
+			case p.frames <- *frame:
+				p.lora.log.Debug("p2p frame", "rssi", frame.RSSI, "snr", frame.SNR, "size", len(frame.Payload))
+			default:
+				p.lora.log.Warn("p2p frame dropped", "rssi", frame.RSSI, "snr", frame.SNR, "size", len(frame.Payload))
+			}
+			continue
+		}
+
+		select {
+		case p.resp <- line:
+		default:
+		}
+
+		// the module answered the last command, the port can be released
+		select {
+		case <-p.quit:
+			return
+		default:
+		}
+	}
+}
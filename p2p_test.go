@@ -0,0 +1,237 @@
+package rak811
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRFConfig_String(t *testing.T) {
+	actual := DefaultRFConfig.String()
+	if actual != "868100000,12,0,1,8,20" {
+		t.Errorf("got %q, want %q", actual, "868100000,12,0,1,8,20")
+	}
+}
+
+func TestRFConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		conf  func(c *RFConfig)
+		valid bool
+	}{
+		{"default", func(c *RFConfig) {}, true},
+		{"spreading factor", func(c *RFConfig) { c.SpreadingFactor = 13 }, false},
+		{"bandwidth", func(c *RFConfig) { c.Bandwidth = 3 }, false},
+		{"coding rate", func(c *RFConfig) { c.CodingRate = 0 }, false},
+		{"preamble", func(c *RFConfig) { c.Preamble = 4 }, false},
+		{"power", func(c *RFConfig) { c.Power = 21 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := DefaultRFConfig
+			tt.conf(&conf)
+			err := conf.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("got %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestParseRFConfig(t *testing.T) {
+	conf, err := ParseRFConfig("OK868100000,12,0,1,8,20")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if conf != DefaultRFConfig {
+		t.Errorf("got %+v, want %+v", conf, DefaultRFConfig)
+	}
+}
+
+func TestParseFrame(t *testing.T) {
+	tests := []struct {
+		in    string
+		frame Frame
+	}{
+		{"at+recv=0,0,-40,7,2:CAFE", Frame{Port: 0, RSSI: -40, SNR: 7, Payload: []byte{0xca, 0xfe}}},
+		{"at+recv=0,2,3:010203", Frame{Port: 2, Payload: []byte{1, 2, 3}}},
+		{"at+recv=0,1,-100,-3,0", Frame{Port: 1, RSSI: -100, SNR: -3, Payload: []byte{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			frame, err := ParseFrame(tt.in)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if frame.Port != tt.frame.Port || frame.RSSI != tt.frame.RSSI || frame.SNR != tt.frame.SNR ||
+				!bytes.Equal(frame.Payload, tt.frame.Payload) {
+				t.Errorf("got %+v, want %+v", *frame, tt.frame)
+			}
+		})
+	}
+
+	for _, in := range []string{"at+recv=2,0,0", "at+recv=0,0,3:01", "at+recv=0,0,1:ZZ", "OK"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParseFrame(in); err == nil {
+				t.Errorf("want error for %q", in)
+			}
+		})
+	}
+}
+
+func TestP2P_Transmit(t *testing.T) {
+	module := newFakeModule(func(cmd string) []string {
+		if strings.HasPrefix(cmd, "txc=") {
+			return []string{OK, "at+recv=9,0,0"}
+		}
+		return []string{OK}
+	})
+	lora, _ := newLora(module)
+
+	p2p, err := NewP2P(lora, DefaultRFConfig)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := p2p.Transmit(ctx, []byte{0xde, 0xad}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if err := p2p.Close(); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	want := []string{"mode=1", "rf_config=868100000,12,0,1,8,20", "rxc=1", "txc=1,0,DEAD", "rxc=1", "rx_stop"}
+	actual := module.Commands()
+	if strings.Join(actual, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", actual, want)
+	}
+}
+
+func TestP2P_Transmit_Error(t *testing.T) {
+	module := newFakeModule(func(cmd string) []string {
+		if strings.HasPrefix(cmd, "txc=") {
+			return []string{"ERROR-13"}
+		}
+		return []string{OK}
+	})
+	lora, _ := newLora(module)
+
+	p2p, err := NewP2P(lora, DefaultRFConfig)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer p2p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err = p2p.Transmit(ctx, []byte{0x01})
+	lerr, ok := err.(*LoraError)
+	if !ok || lerr.Code() != CodeTxLenLimitErr {
+		t.Errorf("got %v, want %d", err, CodeTxLenLimitErr)
+	}
+
+	if err := p2p.Transmit(ctx, make([]byte, MaxP2PPayload+1)); err != ErrPayloadTooLarge {
+		t.Errorf("got %v, want %v", err, ErrPayloadTooLarge)
+	}
+}
+
+func TestP2P_Receive(t *testing.T) {
+	module := newFakeModule(func(cmd string) []string {
+		return []string{OK}
+	})
+	lora, _ := newLora(module)
+
+	p2p, err := NewP2P(lora, DefaultRFConfig)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer p2p.Close()
+
+	module.Emit("at+recv=0,0,-52,9,3:414243")
+
+	select {
+	case frame := <-p2p.Receive():
+		if string(frame.Payload) != "ABC" || frame.RSSI != -52 || frame.SNR != 9 {
+			t.Errorf("got %+v", frame)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+}
+
+// fakeModule answers the commands written to the port with the lines
+// returned by the handler, blocking reads until there is something to
+// return.
+type fakeModule struct {
+	handler func(cmd string) []string
+
+	mu      sync.Mutex
+	cmds    []string
+	out     chan []byte
+	pending []byte
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newFakeModule(handler func(cmd string) []string) *fakeModule {
+	return &fakeModule{
+		handler: handler,
+		out:     make(chan []byte, 64),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (f *fakeModule) Read(p []byte) (int, error) {
+	if len(f.pending) == 0 {
+		select {
+		case b := <-f.out:
+			f.pending = b
+		case <-f.closed:
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
+
+func (f *fakeModule) Write(p []byte) (int, error) {
+	cmd := strings.TrimPrefix(strings.TrimSuffix(string(p), CrLf), "at+")
+
+	f.mu.Lock()
+	f.cmds = append(f.cmds, cmd)
+	f.mu.Unlock()
+
+	for _, line := range f.handler(cmd) {
+		f.Emit(line)
+	}
+	return len(p), nil
+}
+
+func (f *fakeModule) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+
+// Emit sends an unsolicited line to the reader.
+func (f *fakeModule) Emit(line string) {
+	f.out <- []byte(line + CrLf)
+}
+
+// Commands returns the commands written so far.
+func (f *fakeModule) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cmds...)
+}
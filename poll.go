@@ -0,0 +1,240 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval     = 5 * time.Minute
+	defaultFastPollInterval = 10 * time.Second
+)
+
+// keepAlivePayload payload of the keep-alive uplinks without one, the
+// uplinks need data.
+var keepAlivePayload = []byte{0x00}
+
+// PollerConfig configuration of a Poller, zero values use the defaults.
+type PollerConfig struct {
+	// Port FPort of the keep-alive uplinks, defaults to 1.
+	Port uint8
+	// Confirmed sends confirmed keep-alive uplinks.
+	Confirmed bool
+	// Interval between keep-alive uplinks, defaults to 5m.
+	Interval time.Duration
+	// FastInterval between keep-alive uplinks while downlinks are pending
+	// or expected, defaults to 10s.
+	FastInterval time.Duration
+	// Payload returns the payload of the next keep-alive uplink, a single
+	// zero byte is sent when nil or empty.
+	Payload func() []byte
+}
+
+func (c PollerConfig) withDefaults() PollerConfig {
+	if c.Port == 0 {
+		c.Port = 1
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultPollInterval
+	}
+	if c.FastInterval <= 0 {
+		c.FastInterval = defaultFastPollInterval
+	}
+	return c
+}
+
+// Poller fetches the downlinks of a class A device, which are only
+// received after an uplink, by sending keep-alive uplinks and delivering
+// the downlinks to the handlers of their FPort. While it runs, the
+// module must only be used through Do.
+//
+// The module doesn't report the FPending bit, so a downlink is taken as
+// a sign that more are queued: the poller keeps polling at the fast
+// interval until an uplink gets no downlink.
+type Poller struct {
+	lora *Lora
+	conf PollerConfig
+
+	wake chan struct{}
+	// lmu serialises the uses of the module
+	lmu sync.Mutex
+
+	mu sync.Mutex
+	// handlers by port, set on the router instead with a Config.Router
+	// and kept so only the poller's are removed.
+	handlers map[uint8]func(Frame)
+	pending  bool
+	expect   time.Time
+	now      func() time.Time
+}
+
+// NewPoller creates a poller sending its uplinks with l, which must
+// already have joined the network.
+func NewPoller(l *Lora, conf PollerConfig) *Poller {
+	return &Poller{
+		lora:     l,
+		conf:     conf.withDefaults(),
+		wake:     make(chan struct{}, 1),
+		handlers: make(map[uint8]func(Frame)),
+		now:      time.Now,
+	}
+}
+
+// Do runs fn with the module, one call at a time with the polls.
+func (p *Poller) Do(fn func(l *Lora) error) error {
+	p.lmu.Lock()
+	defer p.lmu.Unlock()
+	return fn(p.lora)
+}
+
+// Handle sets the handler of the downlinks received on port, nil removes
+// it. Downlinks without a handler are dropped. With a Config.Router, the
+// handler is set on the router, which receives every downlink, and nil
+// only removes a handler set by the poller.
+func (p *Poller) Handle(port uint8, fn func(Frame)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, installed := p.handlers[port]
+	if fn == nil {
+		delete(p.handlers, port)
+	} else {
+		p.handlers[port] = fn
+	}
+
+	r := p.lora.router
+	switch {
+	case r == nil:
+	case fn != nil:
+		r.HandleDownlink(port, func(ctx context.Context, d Downlink) { fn(d.Frame) })
+	case installed:
+		r.HandleDownlink(port, nil)
+	}
+}
+
+// Expect polls at the fast interval for d, e.g. after requesting a
+// config update, starting with an uplink right away.
+func (p *Poller) Expect(d time.Duration) {
+	p.mu.Lock()
+	if until := p.now().Add(d); until.After(p.expect) {
+		p.expect = until
+	}
+	p.mu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Interval returns the wait before the next keep-alive uplink.
+func (p *Poller) Interval() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending || p.now().Before(p.expect) {
+		return p.conf.FastInterval
+	}
+	return p.conf.Interval
+}
+
+// Poll sends a keep-alive uplink and delivers the downlink received, if
+// any. Returns whether a downlink was received.
+func (p *Poller) Poll() (bool, error) {
+	var payload []byte
+	if p.conf.Payload != nil {
+		payload = p.conf.Payload()
+	}
+	if len(payload) == 0 {
+		payload = keepAlivePayload
+	}
+	confirmed := 0
+	if p.conf.Confirmed {
+		confirmed = 1
+	}
+
+	p.lmu.Lock()
+	resp, err := p.lora.Send(fmt.Sprintf("%d,%d,%X", confirmed, p.conf.Port, payload))
+	p.lmu.Unlock()
+	if err != nil {
+		if lerr := WhichError(resp); lerr != nil {
+			return false, lerr
+		}
+		return false, err
+	}
+
+	evt := WhichEventResponse(resp)
+	if evt == nil {
+		return false, fmt.Errorf("invalid send response: %q", resp)
+	}
+	switch evt.Code() {
+	case StatusTxConfirmed, StatusTxUnconfirmed:
+		p.setPending(false)
+		return false, nil
+	case StatusRecvData:
+	default:
+		return false, fmt.Errorf("send failed: %s", evt.Description())
+	}
+
+	frame, err := ParseFrame(resp)
+	if err != nil {
+		return false, err
+	}
+	p.setPending(true)
+	p.deliver(*frame)
+	return true, nil
+}
+
+func (p *Poller) setPending(pending bool) {
+	p.mu.Lock()
+	p.pending = pending
+	p.mu.Unlock()
+}
+
+// deliver calls the handler of the frame port, unless the router
+// already received it.
+func (p *Poller) deliver(f Frame) {
+	if p.lora.router != nil {
+		return
+	}
+
+	p.mu.Lock()
+	fn := p.handlers[f.Port]
+	p.mu.Unlock()
+
+	if fn == nil {
+		p.lora.log.Debug("downlink without handler dropped", "port", f.Port)
+		return
+	}
+	fn(f)
+}
+
+// Run polls until the context is done. Failed uplinks are retried at the
+// next interval, or once allowed when held back by the duty cycle.
+func (p *Poller) Run(ctx context.Context) error {
+	for {
+		wait := p.Interval()
+		if _, err := p.Poll(); err != nil {
+			var dc *DutyCycleError
+			if errors.As(err, &dc) {
+				wait = max(wait, dc.Wait)
+			}
+			p.lora.log.Warn("poll failed", "error", err, "retry", wait)
+		} else {
+			wait = p.Interval()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-p.wake:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
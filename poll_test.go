@@ -0,0 +1,134 @@
+package rak811
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestPoller_Poll(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPoller(l, PollerConfig{Port: 2, Payload: func() []byte { return []byte{0x01} }})
+	var got []Frame
+	p.Handle(7, func(f Frame) { got = append(got, f) })
+
+	module.QueueDownlink(simulator.Downlink{Port: 7, RSSI: -60, SNR: 5, Payload: []byte{0xaa}})
+	module.QueueDownlink(simulator.Downlink{Port: 9, Payload: []byte{0xbb}})
+
+	if ok, err := p.Poll(); err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if len(got) != 1 || got[0].Port != 7 || got[0].Payload[0] != 0xaa {
+		t.Errorf("got %+v", got)
+	}
+	if d := p.Interval(); d != defaultFastPollInterval {
+		t.Errorf("got interval %s with a downlink pending", d)
+	}
+
+	// no handler for port 9
+	if ok, err := p.Poll(); err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if len(got) != 1 {
+		t.Errorf("got %+v", got)
+	}
+
+	if ok, err := p.Poll(); err != nil || ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if d := p.Interval(); d != defaultPollInterval {
+		t.Errorf("got interval %s without downlinks", d)
+	}
+
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	p.Expect(time.Minute)
+	if d := p.Interval(); d != defaultFastPollInterval {
+		t.Errorf("got interval %s while expecting downlinks", d)
+	}
+	now = now.Add(2 * time.Minute)
+	if d := p.Interval(); d != defaultPollInterval {
+		t.Errorf("got interval %s once expired", d)
+	}
+
+	cmds := module.Commands()
+	if want := "send=0,2,01"; cmds[len(cmds)-1] != want {
+		t.Errorf("got %q, want %q", cmds[len(cmds)-1], want)
+	}
+}
+
+func TestPoller_Run(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	p := NewPoller(l, PollerConfig{Interval: time.Hour, FastInterval: 10 * time.Millisecond})
+	frames := make(chan Frame, 4)
+	p.Handle(3, func(f Frame) { frames <- f })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+
+	// the first uplink fails, not joined yet
+	time.Sleep(50 * time.Millisecond)
+	err := p.Do(func(l *Lora) error {
+		_, err := l.JoinOTAA()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	module.QueueDownlink(simulator.Downlink{Port: 3, Payload: []byte{1}})
+	module.QueueDownlink(simulator.Downlink{Port: 3, Payload: []byte{2}})
+	p.Expect(time.Second)
+
+	for i := byte(1); i <= 2; i++ {
+		select {
+		case f := <-frames:
+			if f.Payload[0] != i {
+				t.Errorf("got %+v, want payload %d", f, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("downlink not delivered")
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v", err)
+	}
+	for _, c := range module.Commands() {
+		if c == "send=0,1,00" {
+			return
+		}
+	}
+	t.Errorf("no keep-alive uplink in %q", module.Commands())
+}
+
+func TestPoller_HandleRouter(t *testing.T) {
+	router := NewRouter()
+	var got []uint8
+	router.HandleDownlink(5, func(ctx context.Context, d Downlink) { got = append(got, d.Port) })
+	l := newSimulatedLora(t, simulator.New(), Config{Router: router})
+	p := NewPoller(l, PollerConfig{})
+
+	// the handler of the application isn't the poller's to remove
+	p.Handle(5, nil)
+	p.Handle(6, func(f Frame) { got = append(got, f.Port+100) })
+	for _, port := range []uint8{5, 6} {
+		_ = router.ServeDownlink(context.Background(), Downlink{Frame: Frame{Port: port}})
+	}
+	p.Handle(6, nil)
+	_ = router.ServeDownlink(context.Background(), Downlink{Frame: Frame{Port: 6}})
+
+	if len(got) != 2 || got[0] != 5 || got[1] != 106 {
+		t.Errorf("got %v", got)
+	}
+}
@@ -1,16 +1,17 @@
 package rak811
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/tarm/serial"
+	"go.opentelemetry.io/otel/trace"
 	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/gpio/gpioreg"
 )
@@ -179,19 +180,91 @@ type extraConfig struct {
 }
 
 type Config struct {
+	// Name serial device path, or the address of a remote module as
+	// tcp://host:port or rfc2217://host:port, or a capture file to play
+	// back as replay://path.
 	Name     string
 	Baud     int
 	Parity   Parity
 	StopBits StopBits
 	Size     uint8
 	Timeout  time.Duration
+	// AutoBaud probes the module baud rate, starting with Baud, before
+	// opening a serial device.
+	AutoBaud bool
+	// BaudRates rates tried by AutoBaud, defaults to DefaultBaudRates.
+	BaudRates []int
+	// ReconnectBackoff wait before reopening a failed port, doubled after
+	// every failed attempt up to a minute. Defaults to 1s.
+	ReconnectBackoff time.Duration
+	// OnStateChange called when the connection to the module changes
+	// state, with the error that caused it if any.
+	OnStateChange func(state ConnState, err error)
+	// Capture records the traffic with the module as JSON lines, the
+	// LoRaWAN keys redacted, see Recorder.
+	Capture io.Writer
+	// Logger receives the commands, replies, events, reconnections and
+	// errors as structured records. Nothing is logged by default.
+	Logger *slog.Logger
+	// Observer is notified of the commands and events, e.g. to export
+	// metrics.
+	Observer Observer
+	// TracerProvider creates a span for every command exchange, with a
+	// child span for every line read. Nothing is traced by default.
+	TracerProvider trace.TracerProvider
+	// DutyCycle holds back the Send, Txc and P2P transmissions exceeding
+	// the duty cycle of their sub-band. Nothing is held back by default.
+	DutyCycle *DutyCycle
+	// Router handles the downlinks received by the module, with the
+	// responses of Send or at any time in class B and C. It replaces the
+	// DownlinkHandlers, and the handlers of a Poller are added to it.
+	Router *Router
 }
 
 type config func(*Config)
 
 type Lora struct {
-	config *extraConfig
-	port   io.ReadWriteCloser
+	config   *extraConfig
+	port     io.ReadWriteCloser
+	lines    *lineScanner
+	log      *slog.Logger
+	observer Observer
+	tracer   trace.Tracer
+
+	// exchange span of the command in progress, written bytes written to
+	// the port so far, ctx parent of the spans set by WithContext.
+	exchange *exchange
+	written  int64
+	ctx      context.Context
+
+	// dutyCycle accounts the airtime, band caches the region of the
+	// module.
+	dutyCycle *DutyCycle
+	band      Region
+
+	// dr caches the data rate of the module, nil until read, drUplinks
+	// counts the uplinks sent since.
+	dr        *DataRate
+	drUplinks int
+	// rf caches the RF config of the module, nil until read.
+	rf *RFConfig
+
+	// reader background reader of class B/C, handlers its downlink
+	// callbacks.
+	reader   *reader
+	handlers DownlinkHandlers
+
+	// router handles the downlinks queued, both nil without one.
+	router    *Router
+	downlinks chan Downlink
+
+	// conf and open reopen the port after an I/O failure, they are only
+	// set when created from a Config.
+	conf    *Config
+	open    func() (io.ReadWriteCloser, error)
+	mu      sync.Mutex
+	state   ConnState
+	closing chan struct{}
 }
 
 func New(conf *Config) (*Lora, error) {
@@ -206,33 +279,84 @@ func New(conf *Config) (*Lora, error) {
 
 	newConfig(conf)(defaultConfig)
 
-	p, err := serial.OpenPort(&serial.Config{
-		Name:        defaultConfig.Name,
-		Baud:        defaultConfig.Baud,
-		ReadTimeout: defaultConfig.Timeout,
-		Size:        defaultConfig.Size,
-		Parity:      serial.Parity(defaultConfig.Parity),
-		StopBits:    serial.StopBits(defaultConfig.StopBits),
-	})
+	if defaultConfig.AutoBaud && isSerial(defaultConfig.Name) {
+		baud, err := probe(defaultConfig, baudRates(defaultConfig))
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect baud rate: %v", err)
+		}
+		defaultConfig.Baud = baud
+	}
+
+	p, err := openPort(defaultConfig)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	return newLora(p)
+	l, err := newLora(p)
+	if err != nil {
+		return nil, err
+	}
+	l.conf = defaultConfig
+	l.log = newLogger(defaultConfig)
+	l.observer = defaultConfig.Observer
+	l.tracer = newTracer(defaultConfig)
+	l.dutyCycle = defaultConfig.DutyCycle
+	if defaultConfig.Router != nil {
+		l.router = defaultConfig.Router
+		l.downlinks = make(chan Downlink, frameBacklog)
+		go l.serveDownlinks(defaultConfig.Router)
+	}
+	l.open = func() (io.ReadWriteCloser, error) {
+		return openPort(defaultConfig)
+	}
+	return l, nil
 }
 
 func newLora(p io.ReadWriteCloser) (*Lora, error) {
 	return &Lora{
 		port:   p,
+		lines:  newLineScanner(p),
+		log:    newLogger(nil),
+		tracer: newTracer(nil),
 		config: &extraConfig{
 			debug: false,
 		},
+		state:   StateConnected,
+		closing: make(chan struct{}),
 	}, nil
 }
 
 func (l *Lora) tx(cmd string, fn func(l *Lora) (string, error)) (string, error) {
-	if _, err := l.port.Write(createCmd(cmd)); err != nil {
-		return "", fmt.Errorf("failed to write command %q with: %v", cmd, err)
+	if err := l.ready(); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	l.startExchange(cmd)
+	resp, err := l.send(cmd, fn)
+	duration := time.Since(start)
+	l.endExchange(resp, err)
+	l.logCommand(cmd, resp, err, duration)
+	l.observeCommand(cmd, resp, err, duration)
+
+	if _, ok := err.(*portError); ok {
+		l.lost(err)
+	}
+	return resp, err
+}
+
+// send writes the command and reads the response with fn.
+func (l *Lora) send(cmd string, fn func(l *Lora) (string, error)) (string, error) {
+	if r := l.backgroundReader(); r != nil {
+		r.begin(repliesWithEvent(cmd))
+		defer r.end()
+	}
+
+	port, _ := l.conn()
+	n, err := port.Write(createCmd(cmd))
+	l.written += int64(n)
+	if err != nil {
+		return "", &portError{fmt.Errorf("failed to write command %q with: %v", cmd, err)}
 	}
 	return fn(l)
 }
@@ -261,12 +385,6 @@ func (l *Lora) tx(cmd string, fn func(l *Lora) (string, error)) (string, error)
 //	return fn(buf[:n])
 //}
 
-func debug(l *Lora, format string) {
-	if l.config.debug {
-		fmt.Printf("%s\n", format)
-	}
-}
-
 //
 // System Commands
 //
@@ -281,9 +399,18 @@ func (l *Lora) Sleep() (string, error) {
 	return l.tx("sleep", readline)
 }
 
-// Debug set debug mode on or off
+// Debug set debug mode on or off. Without a Config.Logger, debug mode
+// logs every exchange with the module to stdout.
 func (l *Lora) Debug(mode bool) {
 	l.config.debug = mode
+	if l.conf != nil && l.conf.Logger != nil {
+		return
+	}
+	if mode {
+		l.log = debugLogger()
+	} else {
+		l.log = newLogger(nil)
+	}
 }
 
 // Reset module or LoRaWAN stack
@@ -309,7 +436,8 @@ func (l *Lora) HardReset() (string, error) {
 	time.Sleep(2000 * time.Millisecond)
 
 	buf := bytes.Buffer{}
-	_, err := buf.ReadFrom(l.port)
+	port, _ := l.conn()
+	_, err := buf.ReadFrom(port)
 	if err != nil {
 		return "", fmt.Errorf("failed reading response: %v", err)
 	}
@@ -343,8 +471,21 @@ func (l *Lora) SetRecvEx(mode int) (string, error) {
 
 // Close the serial conn.
 func (l *Lora) Close() {
-	if err := l.port.Close(); err != nil {
-		fmt.Printf("failed closing conn: %v", err)
+	l.mu.Lock()
+	if l.state == StateClosed {
+		l.mu.Unlock()
+		return
+	}
+	l.mu.Unlock()
+
+	l.setState(StateClosed, nil)
+	close(l.closing)
+
+	l.mu.Lock()
+	p := l.port
+	l.mu.Unlock()
+	if err := p.Close(); err != nil {
+		l.log.Error("failed closing conn", "error", err)
 	}
 }
 
@@ -363,19 +504,40 @@ func (l *Lora) GetConfig(key string) (string, error) {
 }
 
 // GetBand LoRaWAN band region
-func (l *Lora) GetBand() (string, error) {
-	return l.tx("band", readline)
+func (l *Lora) GetBand() (Region, error) {
+	resp, err := l.tx("band", readline)
+	if err != nil {
+		return "", err
+	}
+	if err := isError(resp); err != nil {
+		return "", err
+	}
+	r, err := ParseRegion(resp)
+	if err != nil {
+		return "", err
+	}
+	l.setRegion(r)
+	return r, nil
 }
 
 // SetBand LoRaWAN band region
-func (l *Lora) SetBand(band string) (string, error) {
-	return l.tx(fmt.Sprintf("band=%s", band), readline)
+func (l *Lora) SetBand(region Region) (string, error) {
+	if !region.Valid() {
+		return "", fmt.Errorf("unknown region: %q", region)
+	}
+	l.setRegion("")
+	resp, err := l.tx(fmt.Sprintf("band=%s", region), readline)
+	if err == nil && isOk(resp) {
+		l.setRegion(region)
+	}
+	return resp, err
 }
 
 // JoinOTAA join the configured network in OTAA mode.
 // The module doesn't accept any other command before it returns a response.
 // Response: JoinSuccess, JoinFail, JoinTimeout
 func (l *Lora) JoinOTAA() (string, error) {
+	l.setDataRate(nil)
 	return l.tx("join=otaa", func(l *Lora) (string, error) {
 		resp, err := readline(l)
 		if err != nil {
@@ -437,14 +599,49 @@ func (l *Lora) Signal() (string, error) {
 	return l.tx("signal", readline)
 }
 
-// GetDataRate get next send data rate
-func (l *Lora) GetDataRate() (string, error) {
-	return l.tx("dr", readline)
+// GetDataRate get next send data rate, with its modulation in the
+// region of the module
+func (l *Lora) GetDataRate() (DataRate, error) {
+	r, err := l.region()
+	if err != nil {
+		return DataRate{}, err
+	}
+	resp, err := l.tx("dr", readline)
+	if err != nil {
+		return DataRate{}, err
+	}
+	if err := isError(resp); err != nil {
+		return DataRate{}, err
+	}
+	n, err := parseDataRate(resp)
+	if err != nil {
+		return DataRate{}, err
+	}
+	dr, err := r.DataRate(n)
+	if err != nil {
+		return DataRate{}, err
+	}
+	l.setDataRate(&dr)
+	return dr, nil
 }
 
-// SetDataRate set next send data rate
-func (l *Lora) SetDataRate(datarate string) (string, error) {
-	return l.tx(fmt.Sprintf("dr=%s", datarate), readline)
+// SetDataRate set next send data rate, it must be an uplink data rate
+// of the region of the module
+func (l *Lora) SetDataRate(dr int) (string, error) {
+	r, err := l.region()
+	if err != nil {
+		return "", err
+	}
+	rate, err := r.DataRate(dr)
+	if err != nil {
+		return "", err
+	}
+	l.setDataRate(nil)
+	resp, err := l.tx(fmt.Sprintf("dr=%d", dr), readline)
+	if err == nil && isOk(resp) {
+		l.setDataRate(&rate)
+	}
+	return resp, err
 }
 
 // GetLinkCnt get LoRaWAN uplink and down-link counter
@@ -462,9 +659,18 @@ func (l *Lora) GetABPInfo() (string, error) {
 	return l.tx("abp_info", readline)
 }
 
-// Send sends data to LoRaWAN network, returns the event response
+// Send sends data to LoRaWAN network, returns the event response.
+// With a DutyCycle configured the airtime of the uplink is accounted
+// first, at the band and data rate of the module.
 func (l *Lora) Send(data string) (string, error) {
-	return l.tx(fmt.Sprintf("send=%s", data), func(l *Lora) (string, error) {
+	release := func() {}
+	if l.dutyCycle != nil {
+		var err error
+		if release, err = l.sendAirtime(data); err != nil {
+			return "", err
+		}
+	}
+	resp, err := l.tx(fmt.Sprintf("send=%s", data), func(l *Lora) (string, error) {
 		resp, err := readline(l)
 		if err != nil {
 			return "", err
@@ -479,6 +685,12 @@ func (l *Lora) Send(data string) (string, error) {
 		}
 		return resp, errors.New(resp)
 	})
+	if err != nil {
+		// nothing was sent
+		release()
+	}
+	l.uplinkSent(resp)
+	return resp, err
 	//return l.tx(fmt.Sprintf("send=%s", data), func(b []byte) (string, error) {
 	//	scanner := bufio.NewScanner(bytes.NewReader(b))
 	//	scanner.Split(bufio.ScanLines)
@@ -513,12 +725,31 @@ func (l *Lora) GetRfConfig() (string, error) {
 
 // SetRfConfig Set RF parameters
 func (l *Lora) SetRfConfig(parameters string) (string, error) {
-	return l.tx(fmt.Sprintf("rf_config=%s", parameters), readline)
+	l.setRFConfig(nil)
+	resp, err := l.tx(fmt.Sprintf("rf_config=%s", parameters), readline)
+	if err == nil && isOk(resp) {
+		if rf, err := ParseRFConfig(parameters); err == nil {
+			l.setRFConfig(&rf)
+		}
+	}
+	return resp, err
 }
 
-// Txc send LoraP2P message
+// Txc send LoraP2P message. With a DutyCycle configured the airtime of
+// the frames is accounted first, at the RF config of the module.
 func (l *Lora) Txc(parameters string) (string, error) {
-	return l.tx(fmt.Sprintf("txc=%s", parameters), readline)
+	release := func() {}
+	if l.dutyCycle != nil {
+		var err error
+		if release, err = l.txcAirtime(parameters); err != nil {
+			return "", err
+		}
+	}
+	resp, err := l.tx(fmt.Sprintf("txc=%s", parameters), readline)
+	if err != nil || isError(resp) != nil {
+		release()
+	}
+	return resp, err
 }
 
 // Rxc set module in LoraP2P receive mode
@@ -568,27 +799,51 @@ func (l *Lora) SetUART(configuration string) (string, error) {
 	return l.tx(fmt.Sprintf("uart=%s", configuration), readline)
 }
 
+// readline reads the next reply, an event or the OK/ERROR status.
 func readline(l *Lora) (string, error) {
-	reader := bufio.NewReader(l.port)
+	span := l.startLine()
+	resp, err := nextReply(l)
+	endSpan(span, resp, err)
+	return resp, err
+}
+
+func nextReply(l *Lora) (string, error) {
+	if r := l.backgroundReader(); r != nil {
+		return r.next()
+	}
+
+	_, lines := l.conn()
 	for {
-		resp, err := reader.ReadString('\n')
+		resp, err := lines.next()
 		if err != nil {
 			// serial timeout has triggered
 			if err == io.EOF {
-
+				resp = strings.TrimSpace(lines.pending())
 				if isOk(resp) {
+					lines.reset()
 					return resp, nil
 				}
 
 				if err := isError(resp); err != nil {
+					lines.reset()
 					return "", err
 				}
 				continue // proceed until the global timeout operation kicks in
 			}
-			return "", fmt.Errorf("failed read: %v", err)
+			if err == errLineTooLong {
+				l.log.Warn("dropped over-long line")
+				continue
+			}
+			return "", &portError{fmt.Errorf("failed read: %v", err)}
 		}
 
-		resp = strings.TrimSuffix(strings.TrimSpace(resp), "\r")
+		resp = strings.TrimSpace(resp)
+		if resp == "" {
+			continue
+		}
+		l.logEvent(resp)
+		l.observeEvent(resp)
+		l.route(resp)
 		return resp, nil
 	}
 }
@@ -627,6 +882,16 @@ func newConfig(config *Config) config {
 		if config.Timeout > 0 {
 			defaultConfig.Timeout = config.Timeout
 		}
+		defaultConfig.AutoBaud = config.AutoBaud
+		defaultConfig.BaudRates = config.BaudRates
+		defaultConfig.ReconnectBackoff = config.ReconnectBackoff
+		defaultConfig.OnStateChange = config.OnStateChange
+		defaultConfig.Capture = config.Capture
+		defaultConfig.Logger = config.Logger
+		defaultConfig.Observer = config.Observer
+		defaultConfig.TracerProvider = config.TracerProvider
+		defaultConfig.DutyCycle = config.DutyCycle
+		defaultConfig.Router = config.Router
 	}
 }
 
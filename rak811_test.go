@@ -250,8 +250,8 @@ func TestLora_GetBand(t *testing.T) {
 		if err != nil {
 			t.Errorf("error %v", err)
 		}
-		if bytes.Compare([]byte(res), fsp.At()) != 0 {
-			t.Errorf("got %q, want %q", res, fsp.At())
+		if res != EU868 {
+			t.Errorf("got %q, want %q", res, EU868)
 		}
 	})
 }
@@ -275,6 +275,24 @@ func TestLora_JoinOTAA(t *testing.T) {
 	})
 }
 
+func TestLora_JoinOTAA_SingleChunk(t *testing.T) {
+	fsp := newFakeSerialConn([]byte(OK + CrLf + JoinSuccess + CrLf))
+	lora, err := newLora(fsp)
+	if err != nil {
+		t.Error("failed to instantiate Lora")
+	}
+
+	t.Run("join result in the same read as OK", func(t *testing.T) {
+		res, err := lora.JoinOTAA()
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if res != JoinSuccess {
+			t.Fatalf("got %q, want %q", res, JoinSuccess)
+		}
+	})
+}
+
 func TestLora_JoinOTAA_Failed(t *testing.T) {
 	fsp := newFakeSerialConn([]byte(OK+"\r\n"), []byte(JoinFail+"\r\n"))
 	lora, err := newLora(fsp)
@@ -331,14 +349,14 @@ func TestLora_Signal(t *testing.T) {
 }
 
 func TestLora_GetDataRate(t *testing.T) {
-	fsp := newFakeSerialConn([]byte(OK))
+	fsp := newFakeSerialConn([]byte("OKEU868\r\n"), []byte(OK+CrLf), []byte("OK5\r\n"))
 	lora, err := newLora(fsp)
 	if err != nil {
 		t.Error("failed to instantiate Lora")
 	}
 
 	t.Run("change next data rate", func(t *testing.T) {
-		res, err := lora.SetDataRate("EU868")
+		res, err := lora.SetDataRate(5)
 		if err != nil {
 			t.Errorf("error %v", err)
 		}
@@ -346,6 +364,22 @@ func TestLora_GetDataRate(t *testing.T) {
 			t.Errorf("got %q, want %q", res, fsp.At())
 		}
 	})
+
+	t.Run("reject data rate of another region", func(t *testing.T) {
+		if _, err := lora.SetDataRate(7); err == nil {
+			t.Error("want error for DR7 in EU868")
+		}
+	})
+
+	t.Run("get next data rate", func(t *testing.T) {
+		res, err := lora.GetDataRate()
+		if err != nil {
+			t.Errorf("error %v", err)
+		}
+		if res.String() != "DR5 (SF7BW125)" {
+			t.Errorf("got %q, want %q", res, "DR5 (SF7BW125)")
+		}
+	})
 }
 
 func TestLora_GetLinkCnt(t *testing.T) {
@@ -0,0 +1,204 @@
+package rak811
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	defaultReconnectBackoff = time.Second
+	maxReconnectBackoff     = time.Minute
+
+	// healthCheckTimeout how long the module has to answer the version
+	// command after the port is reopened.
+	healthCheckTimeout = 5 * time.Second
+)
+
+// ErrDisconnected the port failed and hasn't been reopened yet, or the
+// Lora has been closed.
+var ErrDisconnected = errors.New("module disconnected")
+
+// ConnState state of the connection to the module.
+type ConnState int
+
+const (
+	// StateConnected the module answers commands.
+	StateConnected ConnState = iota
+	// StateDisconnected the port failed, it will be reopened after a backoff.
+	StateDisconnected
+	// StateReconnecting the port is being reopened.
+	StateReconnecting
+	// StateClosed the Lora has been closed.
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// portError I/O failure of the port, as opposed to an error reported by
+// the module.
+type portError struct {
+	err error
+}
+
+func (e *portError) Error() string {
+	return e.err.Error()
+}
+
+func (e *portError) Unwrap() error {
+	return e.err
+}
+
+// State returns the state of the connection to the module.
+func (l *Lora) State() ConnState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// ready returns ErrDisconnected unless the module is connected.
+// conn returns the port and its lines, replaced when reconnected.
+func (l *Lora) conn() (io.ReadWriteCloser, *lineScanner) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.port, l.lines
+}
+
+func (l *Lora) ready() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.state != StateConnected {
+		return ErrDisconnected
+	}
+	return nil
+}
+
+func (l *Lora) setState(state ConnState, err error) {
+	l.mu.Lock()
+	if l.state == StateClosed || l.state == state {
+		l.mu.Unlock()
+		return
+	}
+	l.state = state
+	l.mu.Unlock()
+
+	if err != nil {
+		l.log.Warn("connection state", "state", state.String(), "error", err)
+	} else {
+		l.log.Info("connection state", "state", state.String())
+	}
+
+	if l.conf != nil && l.conf.OnStateChange != nil {
+		l.conf.OnStateChange(state, err)
+	}
+}
+
+// lost handles an I/O failure of the port: the port is closed and reopened
+// in the background, when the Lora was created from a Config.
+func (l *Lora) lost(err error) {
+	if l.open == nil {
+		return
+	}
+
+	l.mu.Lock()
+	if l.state != StateConnected {
+		l.mu.Unlock()
+		return
+	}
+	p := l.port
+	l.mu.Unlock()
+
+	l.setState(StateDisconnected, err)
+	_ = p.Close()
+	go l.reconnect()
+}
+
+// reconnect reopens the port with an exponential backoff until the module
+// answers the version command or the Lora is closed.
+func (l *Lora) reconnect() {
+	backoff := l.conf.ReconnectBackoff
+	if backoff <= 0 {
+		backoff = defaultReconnectBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-time.After(backoff):
+		case <-l.closing:
+			return
+		}
+
+		l.setState(StateReconnecting, nil)
+		err := l.reopen()
+		if err == nil {
+			l.setState(StateConnected, nil)
+			return
+		}
+		l.setState(StateDisconnected, err)
+		l.log.Warn("reconnect failed", "attempt", attempt, "backoff", backoff, "error", err)
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+// reopen opens a new port and checks the module answers on it.
+func (l *Lora) reopen() error {
+	p, err := l.open()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	if l.state == StateClosed {
+		l.mu.Unlock()
+		return p.Close()
+	}
+	l.port, l.lines = p, newLineScanner(p)
+	r, lines := l.reader, l.lines
+	l.mu.Unlock()
+
+	if r != nil {
+		go r.run(lines)
+	}
+
+	if err := l.check(p); err != nil {
+		_ = p.Close()
+		return err
+	}
+	return nil
+}
+
+// check sends the version command, closing the port if the module doesn't
+// answer in time so the pending read returns.
+func (l *Lora) check(p io.Closer) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.send("version", readline)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(healthCheckTimeout):
+		_ = p.Close()
+		<-done
+		return errors.New("module didn't answer the health check")
+	}
+}
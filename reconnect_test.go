@@ -0,0 +1,122 @@
+package rak811
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+	"github.com/tarm/serial"
+)
+
+func TestLora_Reconnect(t *testing.T) {
+	module := simulator.New()
+
+	var mu sync.Mutex
+	var ports []*flakyPort
+	failures := 1
+	open := openSerial
+	defer func() { openSerial = open }()
+	openSerial = func(c *serial.Config) (io.ReadWriteCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// the adapter is gone for the first reopen attempt
+		if len(ports) == 1 && failures > 0 {
+			failures--
+			return nil, errors.New("no such file or directory")
+		}
+		p := &flakyPort{ReadWriteCloser: module.Port()}
+		ports = append(ports, p)
+		return p, nil
+	}
+
+	states := make(chan ConnState, 16)
+	lora, err := New(&Config{
+		Name:             "/dev/ttyUSB0",
+		ReconnectBackoff: 10 * time.Millisecond,
+		OnStateChange: func(state ConnState, err error) {
+			states <- state
+		},
+	})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer lora.Close()
+
+	if _, err := lora.Version(); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	mu.Lock()
+	ports[0].Break()
+	mu.Unlock()
+
+	if _, err := lora.Version(); err == nil {
+		t.Fatal("want error on broken port")
+	}
+	if _, err := lora.Version(); err != ErrDisconnected {
+		t.Errorf("got %v, want %v", err, ErrDisconnected)
+	}
+
+	want := []ConnState{StateDisconnected, StateReconnecting, StateDisconnected, StateReconnecting, StateConnected}
+	for _, state := range want {
+		select {
+		case actual := <-states:
+			if actual != state {
+				t.Fatalf("got state %v, want %v", actual, state)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for state %v", state)
+		}
+	}
+
+	if resp, err := lora.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Errorf("got %q, %v after reconnecting", resp, err)
+	}
+
+	lora.Close()
+	if lora.State() != StateClosed {
+		t.Errorf("got state %v, want %v", lora.State(), StateClosed)
+	}
+	if _, err := lora.Version(); err != ErrDisconnected {
+		t.Errorf("got %v, want %v", err, ErrDisconnected)
+	}
+}
+
+// flakyPort port failing every operation once broken, like a USB serial
+// adapter that vanished.
+type flakyPort struct {
+	io.ReadWriteCloser
+
+	mu     sync.Mutex
+	broken bool
+}
+
+func (f *flakyPort) Break() {
+	f.mu.Lock()
+	f.broken = true
+	f.mu.Unlock()
+}
+
+func (f *flakyPort) isBroken() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.broken
+}
+
+func (f *flakyPort) Read(p []byte) (int, error) {
+	if f.isBroken() {
+		return 0, errors.New("input/output error")
+	}
+	return f.ReadWriteCloser.Read(p)
+}
+
+func (f *flakyPort) Write(p []byte) (int, error) {
+	if f.isBroken() {
+		return 0, errors.New("input/output error")
+	}
+	return f.ReadWriteCloser.Write(p)
+}
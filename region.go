@@ -0,0 +1,282 @@
+package rak811
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Region LoRaWAN regional parameters, as understood by at+band.
+type Region string
+
+// Regions supported by the module.
+const (
+	EU868 Region = "EU868"
+	US915 Region = "US915"
+	AU915 Region = "AU915"
+	KR920 Region = "KR920"
+	AS923 Region = "AS923"
+	IN865 Region = "IN865"
+	CN470 Region = "CN470"
+	EU433 Region = "EU433"
+)
+
+// Regions every region supported by the module.
+var Regions = []Region{EU868, US915, AU915, KR920, AS923, IN865, CN470, EU433}
+
+// ParseRegion parses the at+band response, with or without the OK prefix.
+func ParseRegion(resp string) (Region, error) {
+	r := Region(strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(resp, OK))))
+	if _, ok := plans[r]; !ok {
+		return "", fmt.Errorf("unknown region: %q", resp)
+	}
+	return r, nil
+}
+
+// Valid reports whether the region is supported by the module.
+func (r Region) Valid() bool {
+	_, ok := plans[r]
+	return ok
+}
+
+// Plan returns the channel plan of the region, the zero plan when not
+// supported. The plan must not be modified.
+func (r Region) Plan() ChannelPlan {
+	if p, ok := plans[r]; ok {
+		return *p
+	}
+	return ChannelPlan{}
+}
+
+// DataRate returns the uplink data rate dr of the region.
+func (r Region) DataRate(dr int) (DataRate, error) {
+	rates := r.Plan().DataRates
+	if dr < 0 || dr >= len(rates) {
+		return DataRate{}, fmt.Errorf("invalid data rate %d for region %s", dr, r)
+	}
+	return rates[dr], nil
+}
+
+// DataRate LoRa modulation of a LoRaWAN data rate.
+type DataRate struct {
+	DR              int
+	SpreadingFactor int
+	Bandwidth       Bandwidth
+}
+
+// String formats the data rate as DR5 (SF7BW125).
+func (d DataRate) String() string {
+	return fmt.Sprintf("DR%d (SF%dBW%d)", d.DR, d.SpreadingFactor, d.Bandwidth.Hz()/1000)
+}
+
+// Modulation returns the modulation of the data rate, with the 8 symbols
+// preamble and 4/5 coding rate of LoRaWAN.
+func (d DataRate) Modulation() Modulation {
+	return NewModulation(d.SpreadingFactor, d.Bandwidth, CR4_5, 8)
+}
+
+// Channel uplink channel of a channel plan.
+type Channel struct {
+	// Frequency in hertz.
+	Frequency int
+	MinDR     int
+	MaxDR     int
+}
+
+// ChannelPlan regional parameters of a region.
+type ChannelPlan struct {
+	Region Region
+	// Channels default uplink channels.
+	Channels []Channel
+	// DataRates LoRa uplink data rates, indexed by DR.
+	DataRates []DataRate
+	// MaxEIRP default maximum EIRP in dBm.
+	MaxEIRP float64
+	// RX2Frequency and RX2DataRate default parameters of the second
+	// receive window.
+	RX2Frequency int
+	RX2DataRate  int
+	// SubBands sub-bands with a duty cycle, none when the region has no
+	// duty cycle.
+	SubBands []SubBand
+}
+
+// plans channel plans by region, from the LoRaWAN regional parameters.
+var plans = map[Region]*ChannelPlan{
+	EU868: {
+		Region:       EU868,
+		Channels:     channels(868100000, 200000, 3, 0, 5),
+		DataRates:    dataRates(euDataRates),
+		MaxEIRP:      16,
+		RX2Frequency: 869525000,
+		RX2DataRate:  0,
+		SubBands: []SubBand{
+			{863000000, 865000000, 0.001},
+			{865000000, 868000000, 0.01},
+			{868000000, 868600000, 0.01},
+			{868700000, 869200000, 0.001},
+			{869400000, 869650000, 0.1},
+			{869700000, 870000000, 0.01},
+		},
+	},
+	US915: {
+		Region:       US915,
+		Channels:     append(channels(902300000, 200000, 64, 0, 3), channels(903000000, 1600000, 8, 4, 4)...),
+		DataRates:    dataRates([]DataRate{{SpreadingFactor: 10}, {SpreadingFactor: 9}, {SpreadingFactor: 8}, {SpreadingFactor: 7}, {SpreadingFactor: 8, Bandwidth: BW500}}),
+		MaxEIRP:      30,
+		RX2Frequency: 923300000,
+		RX2DataRate:  8,
+	},
+	AU915: {
+		Region:       AU915,
+		Channels:     append(channels(915200000, 200000, 64, 0, 5), channels(915900000, 1600000, 8, 6, 6)...),
+		DataRates:    dataRates(append(euDataRates[:6:6], DataRate{SpreadingFactor: 8, Bandwidth: BW500})),
+		MaxEIRP:      30,
+		RX2Frequency: 923300000,
+		RX2DataRate:  8,
+	},
+	KR920: {
+		Region:       KR920,
+		Channels:     channels(922100000, 200000, 3, 0, 5),
+		DataRates:    dataRates(euDataRates[:6]),
+		MaxEIRP:      14,
+		RX2Frequency: 921900000,
+		RX2DataRate:  0,
+	},
+	AS923: {
+		Region:       AS923,
+		Channels:     channels(923200000, 200000, 2, 0, 5),
+		DataRates:    dataRates(euDataRates),
+		MaxEIRP:      16,
+		RX2Frequency: 923200000,
+		RX2DataRate:  2,
+	},
+	IN865: {
+		Region: IN865,
+		Channels: []Channel{
+			{865062500, 0, 5},
+			{865402500, 0, 5},
+			{865985000, 0, 5},
+		},
+		DataRates:    dataRates(euDataRates[:6]),
+		MaxEIRP:      30,
+		RX2Frequency: 866550000,
+		RX2DataRate:  2,
+	},
+	CN470: {
+		Region:       CN470,
+		Channels:     channels(470300000, 200000, 96, 0, 5),
+		DataRates:    dataRates(euDataRates[:6]),
+		MaxEIRP:      19.15,
+		RX2Frequency: 505300000,
+		RX2DataRate:  0,
+	},
+	EU433: {
+		Region:       EU433,
+		Channels:     channels(433175000, 200000, 3, 0, 5),
+		DataRates:    dataRates(euDataRates),
+		MaxEIRP:      12.15,
+		RX2Frequency: 434665000,
+		RX2DataRate:  0,
+		SubBands: []SubBand{
+			{433175000, 434665000, 0.01},
+		},
+	},
+}
+
+// euDataRates LoRa data rates shared by most regions, SF12 to SF7 at
+// 125 kHz then SF7 at 250 kHz.
+var euDataRates = []DataRate{
+	{SpreadingFactor: 12}, {SpreadingFactor: 11}, {SpreadingFactor: 10},
+	{SpreadingFactor: 9}, {SpreadingFactor: 8}, {SpreadingFactor: 7},
+	{SpreadingFactor: 7, Bandwidth: BW250},
+}
+
+// dataRates returns a copy of the data rates numbered by index.
+func dataRates(rates []DataRate) []DataRate {
+	numbered := make([]DataRate, len(rates))
+	for i, r := range rates {
+		r.DR = i
+		numbered[i] = r
+	}
+	return numbered
+}
+
+// channels returns n channels spaced by step hertz from first.
+func channels(first, step, n, minDR, maxDR int) []Channel {
+	chs := make([]Channel, n)
+	for i := range chs {
+		chs[i] = Channel{Frequency: first + i*step, MinDR: minDR, MaxDR: maxDR}
+	}
+	return chs
+}
+
+// parseDataRate parses the at+dr response, with or without the OK prefix.
+func parseDataRate(resp string) (int, error) {
+	dr, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(resp, OK)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid data rate: %q", resp)
+	}
+	return dr, nil
+}
+
+// region returns the region of the module, read once.
+func (l *Lora) region() (Region, error) {
+	l.mu.Lock()
+	r := l.band
+	l.mu.Unlock()
+	if r != "" {
+		return r, nil
+	}
+	return l.GetBand()
+}
+
+// setRegion caches the region, a new one invalidates the data rate.
+func (l *Lora) setRegion(r Region) {
+	l.mu.Lock()
+	if r != l.band {
+		l.dr = nil
+	}
+	l.band = r
+	l.mu.Unlock()
+}
+
+// maxDataRateUplinks uplinks sent at the cached data rate before it is
+// read again: with ADR, the module lowers the data rate on its own after
+// 64 uplinks without a downlink.
+const maxDataRateUplinks = 32
+
+// dataRate returns the data rate of the module, read once and again
+// after SetBand, JoinOTAA, a downlink, which may carry an ADR command from
+// the network, or maxDataRateUplinks uplinks.
+func (l *Lora) dataRate() (DataRate, error) {
+	l.mu.Lock()
+	dr := l.dr
+	l.mu.Unlock()
+	if dr != nil {
+		return *dr, nil
+	}
+	return l.GetDataRate()
+}
+
+func (l *Lora) setDataRate(dr *DataRate) {
+	l.mu.Lock()
+	l.dr = dr
+	l.drUplinks = 0
+	l.mu.Unlock()
+}
+
+// uplinkSent invalidates the cached data rate unless the uplink got no
+// downlink, or after maxDataRateUplinks uplinks.
+func (l *Lora) uplinkSent(resp string) {
+	evt := WhichEventResponse(resp)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.drUplinks++
+	if evt == nil || evt.Code() != StatusTxUnconfirmed || l.drUplinks >= maxDataRateUplinks {
+		l.dr = nil
+		l.drUplinks = 0
+	}
+}
@@ -0,0 +1,112 @@
+package rak811
+
+import (
+	"testing"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestParseRegion(t *testing.T) {
+	for _, r := range Regions {
+		got, err := ParseRegion(OK + string(r))
+		if err != nil || got != r {
+			t.Errorf("got %q, %v, want %q", got, err, r)
+		}
+	}
+	if r, err := ParseRegion("eu868"); err != nil || r != EU868 {
+		t.Errorf("got %q, %v", r, err)
+	}
+	if _, err := ParseRegion("OKXX000"); err == nil {
+		t.Error("want error for an unknown region")
+	}
+}
+
+func TestRegion_Plan(t *testing.T) {
+	for _, r := range Regions {
+		p := r.Plan()
+		if p.Region != r || len(p.Channels) == 0 || len(p.DataRates) == 0 || p.MaxEIRP == 0 || p.RX2Frequency == 0 {
+			t.Errorf("%s: incomplete plan %+v", r, p)
+		}
+		for i, dr := range p.DataRates {
+			if dr.DR != i || dr.SpreadingFactor < 7 || dr.SpreadingFactor > 12 || dr.Bandwidth.Hz() == 0 {
+				t.Errorf("%s: invalid data rate %d: %+v", r, i, dr)
+			}
+		}
+		for _, ch := range p.Channels {
+			if ch.MaxDR >= len(p.DataRates) {
+				t.Errorf("%s: channel %d up to DR%d", r, ch.Frequency, ch.MaxDR)
+			}
+		}
+	}
+
+	if n := len(US915.Plan().Channels); n != 72 {
+		t.Errorf("got %d US915 channels", n)
+	}
+	if ch := US915.Plan().Channels[64]; ch.Frequency != 903000000 || ch.MinDR != 4 {
+		t.Errorf("got %+v", ch)
+	}
+	if len(US915.Plan().SubBands) != 0 || len(EU868.Plan().SubBands) != 6 {
+		t.Error("only EU868 has duty cycle sub-bands")
+	}
+	if p := Region("XX000").Plan(); p.Region != "" {
+		t.Errorf("got %+v", p)
+	}
+}
+
+func TestRegion_DataRate(t *testing.T) {
+	tests := []struct {
+		region Region
+		dr     int
+		want   string
+	}{
+		{EU868, 0, "DR0 (SF12BW125)"},
+		{EU868, 6, "DR6 (SF7BW250)"},
+		{US915, 0, "DR0 (SF10BW125)"},
+		{US915, 4, "DR4 (SF8BW500)"},
+		{AU915, 6, "DR6 (SF8BW500)"},
+	}
+	for _, tt := range tests {
+		dr, err := tt.region.DataRate(tt.dr)
+		if err != nil || dr.String() != tt.want {
+			t.Errorf("%s DR%d: got %s, %v, want %s", tt.region, tt.dr, dr, err, tt.want)
+		}
+	}
+
+	if _, err := US915.DataRate(5); err == nil {
+		t.Error("want error for US915 DR5")
+	}
+	if _, err := EU868.DataRate(-1); err == nil {
+		t.Error("want error for a negative data rate")
+	}
+}
+
+func TestLora_SetBand(t *testing.T) {
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{})
+
+	if _, err := l.SetBand(Region("XX000")); err == nil {
+		t.Fatal("want error for an unknown region")
+	}
+	if _, err := l.SetBand(US915); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := l.GetBand(); err != nil || r != US915 {
+		t.Errorf("got %q, %v", r, err)
+	}
+
+	// validated against the region set, without reading it back
+	if _, err := l.SetDataRate(5); err == nil {
+		t.Error("want error for US915 DR5")
+	}
+	if _, err := l.SetDataRate(4); err != nil {
+		t.Fatal(err)
+	}
+	if dr, err := l.GetDataRate(); err != nil || dr.SpreadingFactor != 8 || dr.Bandwidth != BW500 {
+		t.Errorf("got %s, %v", dr, err)
+	}
+
+	want := []string{"band=US915", "band", "dr=4", "dr"}
+	if cmds := module.Commands(); !equalStrings(cmds, want) {
+		t.Errorf("got %q, want %q", cmds, want)
+	}
+}
@@ -0,0 +1,217 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// ReliableHeaderSize bytes prepended to every frame: type and sequence.
+	ReliableHeaderSize = 2
+
+	reliableData = 0x01
+	reliableAck  = 0x02
+)
+
+// ErrNoAck the peer didn't acknowledge the frame after every retry.
+var ErrNoAck = errors.New("frame not acknowledged")
+
+// ReliableConfig retransmission settings, zero values use the defaults.
+type ReliableConfig struct {
+	// Window maximum number of unacknowledged frames in flight, defaults to 1.
+	Window int
+	// Retries retransmissions before giving up, defaults to 3.
+	Retries int
+	// Timeout wait for the first acknowledgement, defaults to 2s.
+	Timeout time.Duration
+	// Backoff multiplies the timeout after every retransmission, defaults to 2.
+	Backoff float64
+}
+
+func (c ReliableConfig) withDefaults() ReliableConfig {
+	if c.Window <= 0 {
+		c.Window = 1
+	}
+	if c.Retries <= 0 {
+		c.Retries = 3
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 2 * time.Second
+	}
+	if c.Backoff < 1 {
+		c.Backoff = 2
+	}
+	return c
+}
+
+// span how long a frame can be retransmitted for.
+func (c ReliableConfig) span() time.Duration {
+	span, timeout := time.Duration(0), c.Timeout
+	for i := 0; i <= c.Retries; i++ {
+		span += timeout
+		timeout = time.Duration(float64(timeout) * c.Backoff)
+	}
+	return span
+}
+
+// Reliable acknowledged link to a single peer on top of an unreliable one.
+// Every frame carries a sequence number, the receiver acknowledges it and
+// the sender retransmits until it is acknowledged. Retransmitted frames
+// already received are acknowledged again but delivered only once.
+//
+// Sequence numbers are kept per link, so there must be one Reliable per
+// peer; use a link per peer when more than two nodes share the channel.
+type Reliable struct {
+	link   Link
+	config ReliableConfig
+
+	window chan struct{}
+	frames chan Frame
+
+	mu      sync.Mutex
+	seq     uint8
+	pending map[uint8]chan struct{}
+	seen    map[uint8]time.Time
+}
+
+// NewReliable starts acknowledging the frames received on link.
+func NewReliable(link Link, config ReliableConfig) *Reliable {
+	config = config.withDefaults()
+	r := &Reliable{
+		link:    link,
+		config:  config,
+		window:  make(chan struct{}, config.Window),
+		frames:  make(chan Frame, frameBacklog),
+		seq:     uint8(rand.Intn(256)),
+		pending: make(map[uint8]chan struct{}),
+		seen:    make(map[uint8]time.Time),
+	}
+	go r.run()
+	return r
+}
+
+// Transmit sends the payload and waits until the peer acknowledges it.
+// It blocks while the window is full.
+func (r *Reliable) Transmit(ctx context.Context, payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyPayload
+	}
+
+	select {
+	case r.window <- struct{}{}:
+		defer func() { <-r.window }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	seq, acked := r.register()
+	defer r.unregister(seq)
+
+	frame := append([]byte{reliableData, seq}, payload...)
+	timeout := r.config.Timeout
+	for attempt := 0; attempt <= r.config.Retries; attempt++ {
+		if err := r.link.Transmit(ctx, frame); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(timeout)
+		select {
+		case <-acked:
+			timer.Stop()
+			return nil
+		case <-timer.C:
+			timeout = time.Duration(float64(timeout) * r.config.Backoff)
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	return ErrNoAck
+}
+
+// Receive returns the channel where received frames are delivered, without
+// the reliable header. The channel is closed when the underlying link
+// receive channel is closed.
+func (r *Reliable) Receive() <-chan Frame {
+	return r.frames
+}
+
+func (r *Reliable) register() (uint8, chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// skip sequences still in flight after a wrap around
+	for r.pending[r.seq] != nil {
+		r.seq++
+	}
+	seq := r.seq
+	r.seq++
+
+	acked := make(chan struct{})
+	r.pending[seq] = acked
+	return seq, acked
+}
+
+func (r *Reliable) unregister(seq uint8) {
+	r.mu.Lock()
+	delete(r.pending, seq)
+	r.mu.Unlock()
+}
+
+func (r *Reliable) run() {
+	defer close(r.frames)
+
+	for frame := range r.link.Receive() {
+		if len(frame.Payload) < ReliableHeaderSize {
+			continue
+		}
+
+		kind, seq := frame.Payload[0], frame.Payload[1]
+		switch kind {
+		case reliableAck:
+			r.mu.Lock()
+			if acked, ok := r.pending[seq]; ok {
+				close(acked)
+				delete(r.pending, seq)
+			}
+			r.mu.Unlock()
+		case reliableData:
+			r.ack(seq)
+			if r.duplicate(seq, time.Now()) {
+				continue
+			}
+			frame.Payload = frame.Payload[ReliableHeaderSize:]
+			select {
+			case r.frames <- frame:
+			default:
+			}
+		}
+	}
+}
+
+func (r *Reliable) ack(seq uint8) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+	defer cancel()
+
+	// a lost ack is recovered by the peer retransmission
+	_ = r.link.Transmit(ctx, []byte{reliableAck, seq})
+}
+
+// duplicate reports whether seq was received within the retransmission
+// span, remembering it otherwise.
+func (r *Reliable) duplicate(seq uint8, now time.Time) bool {
+	for s, at := range r.seen {
+		if now.Sub(at) > r.config.span() {
+			delete(r.seen, s)
+		}
+	}
+
+	if _, ok := r.seen[seq]; ok {
+		return true
+	}
+	r.seen[seq] = now
+	return false
+}
@@ -0,0 +1,76 @@
+package rak811
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReliable_Transmit(t *testing.T) {
+	// lose every fifth frame, data or ack
+	medium := newMemMedium(func(n int) bool { return n%5 == 0 })
+	config := ReliableConfig{Window: 4, Timeout: 20 * time.Millisecond, Retries: 5}
+	a := NewReliable(medium.Link(), config)
+	b := NewReliable(medium.Link(), config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(map[string]int)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for len(received) < 20 {
+			select {
+			case frame := <-b.Receive():
+				received[string(frame.Payload)]++
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- a.Transmit(ctx, []byte(fmt.Sprintf("msg-%d", i)))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+	}
+
+	<-done
+	if len(received) != 20 {
+		t.Fatalf("got %d messages, want 20", len(received))
+	}
+
+	// retransmissions of frames whose ack was lost must not be delivered
+	select {
+	case frame := <-b.Receive():
+		t.Errorf("got duplicate %q", frame.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReliable_Transmit_NoAck(t *testing.T) {
+	medium := newMemMedium(nil)
+	r := NewReliable(medium.Link(), ReliableConfig{Timeout: 5 * time.Millisecond, Retries: 2})
+
+	if err := r.Transmit(context.Background(), []byte("lost")); err != ErrNoAck {
+		t.Errorf("got %v, want %v", err, ErrNoAck)
+	}
+	if medium.sent != 3 {
+		t.Errorf("got %d transmissions, want 3", medium.sent)
+	}
+}
@@ -0,0 +1,181 @@
+package rak811
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Downlink data frame received from the server.
+type Downlink struct {
+	Frame
+	// Received time the module reported the frame.
+	Received time.Time
+	// Value decoded payload, set by DecodeDownlinks.
+	Value any
+}
+
+// DownlinkFunc handles a downlink. The context is cancelled when the Lora
+// is closed.
+type DownlinkFunc func(ctx context.Context, d Downlink)
+
+// DownlinkMiddleware wraps a handler, e.g. to log or decode the
+// downlinks.
+type DownlinkMiddleware func(next DownlinkFunc) DownlinkFunc
+
+// HandlerPanicError a downlink handler panicked.
+type HandlerPanicError struct {
+	Port  uint8
+	Value any
+	Stack []byte
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("downlink handler of port %d panicked: %v", e.Port, e.Value)
+}
+
+// Router dispatches the downlinks to the handler of their FPort, or to
+// the default handler. A panicking handler doesn't affect the others.
+type Router struct {
+	mu         sync.RWMutex
+	handlers   map[uint8]DownlinkFunc
+	fallback   DownlinkFunc
+	middleware []DownlinkMiddleware
+}
+
+// NewRouter creates a router without handlers, which drops every
+// downlink.
+func NewRouter() *Router {
+	return &Router{handlers: make(map[uint8]DownlinkFunc)}
+}
+
+// HandleDownlink sets the handler of the downlinks received on port, nil
+// removes it.
+func (r *Router) HandleDownlink(port uint8, fn DownlinkFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fn == nil {
+		delete(r.handlers, port)
+		return
+	}
+	r.handlers[port] = fn
+}
+
+// HandleDefault sets the handler of the downlinks received on the ports
+// without a handler.
+func (r *Router) HandleDefault(fn DownlinkFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = fn
+}
+
+// Use adds middleware wrapping every handler, the first added is the
+// outermost.
+func (r *Router) Use(mw ...DownlinkMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
+// ServeDownlink calls the handler of the downlink port through the
+// middleware. Returns a HandlerPanicError if the handler or the
+// middleware panicked.
+func (r *Router) ServeDownlink(ctx context.Context, d Downlink) (err error) {
+	r.mu.RLock()
+	fn, ok := r.handlers[d.Port]
+	if !ok {
+		fn = r.fallback
+	}
+	mw := r.middleware
+	r.mu.RUnlock()
+
+	if fn == nil {
+		return nil
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		fn = mw[i](fn)
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = &HandlerPanicError{Port: d.Port, Value: v, Stack: debug.Stack()}
+		}
+	}()
+	fn(ctx, d)
+	return nil
+}
+
+// LogDownlinks logs every downlink before handling it.
+func LogDownlinks(logger *slog.Logger) DownlinkMiddleware {
+	return func(next DownlinkFunc) DownlinkFunc {
+		return func(ctx context.Context, d Downlink) {
+			logger.InfoContext(ctx, "downlink",
+				"port", d.Port, "rssi", d.RSSI, "snr", d.SNR, "size", len(d.Payload))
+			next(ctx, d)
+		}
+	}
+}
+
+// DecodeDownlinks sets the Value of the downlinks to their decoded
+// payload. The downlinks failing to decode are passed to onError, or
+// dropped when nil, instead of the handler.
+func DecodeDownlinks(decode func(payload []byte) (any, error), onError func(Downlink, error)) DownlinkMiddleware {
+	return func(next DownlinkFunc) DownlinkFunc {
+		return func(ctx context.Context, d Downlink) {
+			v, err := decode(d.Payload)
+			if err != nil {
+				if onError != nil {
+					onError(d, err)
+				}
+				return
+			}
+			d.Value = v
+			next(ctx, d)
+		}
+	}
+}
+
+// route queues the data frame of an at+recv event for the router, other
+// lines are ignored.
+func (l *Lora) route(line string) {
+	if l.downlinks == nil {
+		return
+	}
+	evt := WhichEventResponse(line)
+	if evt == nil || evt.Code() != StatusRecvData {
+		return
+	}
+	frame, err := ParseFrame(line)
+	if err != nil {
+		// already reported by the reader of the line
+		return
+	}
+
+	select {
+	case l.downlinks <- Downlink{Frame: *frame, Received: time.Now()}:
+	default:
+		l.log.Warn("downlink dropped", "port", frame.Port)
+	}
+}
+
+// serveDownlinks passes the downlinks to the router, in order, until the
+// Lora is closed.
+func (l *Lora) serveDownlinks(r *Router) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for {
+		select {
+		case d := <-l.downlinks:
+			if err := r.ServeDownlink(ctx, d); err != nil {
+				l.log.Error("downlink handler failed", "port", d.Port, "error", err)
+			}
+		case <-l.closing:
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package rak811
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestRouter_ServeDownlink(t *testing.T) {
+	r := NewRouter()
+	var got []string
+	r.HandleDownlink(7, func(ctx context.Context, d Downlink) {
+		got = append(got, "7:"+string(d.Payload))
+	})
+	r.HandleDownlink(8, func(ctx context.Context, d Downlink) {
+		panic("boom")
+	})
+
+	ctx := context.Background()
+	if err := r.ServeDownlink(ctx, Downlink{Frame: Frame{Port: 9}}); err != nil {
+		t.Errorf("got %v without a default handler", err)
+	}
+	r.HandleDefault(func(ctx context.Context, d Downlink) {
+		got = append(got, "default")
+	})
+
+	for _, port := range []uint8{7, 9} {
+		if err := r.ServeDownlink(ctx, Downlink{Frame: Frame{Port: port, Payload: []byte("a")}}); err != nil {
+			t.Errorf("port %d: %v", port, err)
+		}
+	}
+	if want := []string{"7:a", "default"}; !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	err := r.ServeDownlink(ctx, Downlink{Frame: Frame{Port: 8}})
+	var perr *HandlerPanicError
+	if !errors.As(err, &perr) || perr.Port != 8 || perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Errorf("got %v", err)
+	}
+
+	r.HandleDownlink(7, nil)
+	got = nil
+	r.ServeDownlink(ctx, Downlink{Frame: Frame{Port: 7}})
+	if want := []string{"default"}; !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRouter_Use(t *testing.T) {
+	r := NewRouter()
+	var got []string
+	trace := func(name string) DownlinkMiddleware {
+		return func(next DownlinkFunc) DownlinkFunc {
+			return func(ctx context.Context, d Downlink) {
+				got = append(got, name)
+				next(ctx, d)
+			}
+		}
+	}
+	r.Use(trace("outer"), trace("inner"))
+
+	var decodeErr error
+	decode := DecodeDownlinks(func(payload []byte) (any, error) {
+		if len(payload) == 0 {
+			return nil, errors.New("empty")
+		}
+		return strings.ToUpper(string(payload)), nil
+	}, func(d Downlink, err error) { decodeErr = err })
+	r.HandleDownlink(1, decode(func(ctx context.Context, d Downlink) {
+		got = append(got, d.Value.(string))
+	}))
+
+	ctx := context.Background()
+	r.ServeDownlink(ctx, Downlink{Frame: Frame{Port: 1, Payload: []byte("on")}})
+	if want := []string{"outer", "inner", "ON"}; !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got = nil
+	r.ServeDownlink(ctx, Downlink{Frame: Frame{Port: 1}})
+	if want := []string{"outer", "inner"}; !equalStrings(got, want) || decodeErr == nil {
+		t.Errorf("got %q, %v", got, decodeErr)
+	}
+}
+
+func TestLora_Router(t *testing.T) {
+	router := NewRouter()
+	downlinks := make(chan Downlink, 4)
+	router.HandleDownlink(5, func(ctx context.Context, d Downlink) {
+		downlinks <- d
+	})
+	router.HandleDownlink(6, func(ctx context.Context, d Downlink) {
+		panic("boom")
+	})
+
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{Router: router})
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+
+	next := func() Downlink {
+		t.Helper()
+		select {
+		case d := <-downlinks:
+			return d
+		case <-time.After(5 * time.Second):
+			t.Fatal("downlink not routed")
+		}
+		return Downlink{}
+	}
+
+	// class A, with the response of an uplink
+	module.QueueDownlink(simulator.Downlink{Port: 6, Payload: []byte{0}})
+	module.QueueDownlink(simulator.Downlink{Port: 5, RSSI: -70, SNR: 4, Payload: []byte{1}})
+	for i := 0; i < 2; i++ {
+		if _, err := l.Send("0,1,00"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := next(); d.RSSI != -70 || d.Payload[0] != 1 || d.Received.IsZero() {
+		t.Errorf("got %+v", d)
+	}
+
+	// class C, at any time
+	if _, err := l.SetClass(ClassC); err != nil {
+		t.Fatal(err)
+	}
+	module.Emit("at+recv=0,5,-80,3,1:02")
+	if d := next(); d.Payload[0] != 2 {
+		t.Errorf("got %+v", d)
+	}
+}
+
+func TestLora_RouterReplacesHandlers(t *testing.T) {
+	router := NewRouter()
+	module := simulator.New()
+	l := newSimulatedLora(t, module, Config{Router: router})
+	if _, err := l.JoinOTAA(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the poller handlers are served by the router, once
+	frames := make(chan Frame, 4)
+	p := NewPoller(l, PollerConfig{})
+	p.Handle(7, func(f Frame) { frames <- f })
+	module.QueueDownlink(simulator.Downlink{Port: 7, Payload: []byte{1}})
+	if ok, err := p.Poll(); err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if f := nextFrame(t, frames); f.Payload[0] != 1 {
+		t.Errorf("got %+v", f)
+	}
+
+	// the class C handlers aren't called
+	unicast := make(chan Frame, 4)
+	l.HandleDownlinks(DownlinkHandlers{Unicast: func(f Frame) { unicast <- f }})
+	if _, err := l.SetClass(ClassC); err != nil {
+		t.Fatal(err)
+	}
+	module.Emit("at+recv=0,7,-80,3,1:02")
+	if f := nextFrame(t, frames); f.Payload[0] != 2 {
+		t.Errorf("got %+v", f)
+	}
+
+	select {
+	case f := <-frames:
+		t.Errorf("got %+v twice", f)
+	case f := <-unicast:
+		t.Errorf("got %+v with a router", f)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
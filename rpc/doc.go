@@ -0,0 +1,10 @@
+// Package rpc serves RAK811 modules over gRPC, see rak811.proto for the
+// service definition.
+//
+//	srv := grpc.NewServer()
+//	s := rpc.NewServer()
+//	s.AddDevice("gateway", lora)
+//	rpc.RegisterLoraServer(srv, s)
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative rak811.proto
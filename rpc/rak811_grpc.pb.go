@@ -0,0 +1,668 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: rak811.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Lora_Version_FullMethodName     = "/rak811.v1.Lora/Version"
+	Lora_GetConfig_FullMethodName   = "/rak811.v1.Lora/GetConfig"
+	Lora_SetConfig_FullMethodName   = "/rak811.v1.Lora/SetConfig"
+	Lora_Join_FullMethodName        = "/rak811.v1.Lora/Join"
+	Lora_Send_FullMethodName        = "/rak811.v1.Lora/Send"
+	Lora_Signal_FullMethodName      = "/rak811.v1.Lora/Signal"
+	Lora_RadioStatus_FullMethodName = "/rak811.v1.Lora/RadioStatus"
+	Lora_GetRfConfig_FullMethodName = "/rak811.v1.Lora/GetRfConfig"
+	Lora_SetRfConfig_FullMethodName = "/rak811.v1.Lora/SetRfConfig"
+	Lora_StartP2P_FullMethodName    = "/rak811.v1.Lora/StartP2P"
+	Lora_StopP2P_FullMethodName     = "/rak811.v1.Lora/StopP2P"
+	Lora_P2PTransmit_FullMethodName = "/rak811.v1.Lora/P2PTransmit"
+	Lora_P2PReceive_FullMethodName  = "/rak811.v1.Lora/P2PReceive"
+	Lora_Events_FullMethodName      = "/rak811.v1.Lora/Events"
+)
+
+// LoraClient is the client API for Lora service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Lora controls the RAK811 modules of a server. Every request names the
+// module with device, it can be left empty when the server has a single
+// module.
+//
+// Errors reported by a module carry an ErrorInfo detail with the domain
+// rak811, the reason MODULE_ERROR and the module error code in the code
+// metadata.
+type LoraClient interface {
+	// Version returns the firmware version.
+	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+	// GetConfig reads LoRaWAN configuration values.
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	// SetConfig writes LoRaWAN configuration values.
+	SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error)
+	// Join joins the LoRaWAN network.
+	Join(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*JoinResponse, error)
+	// Send sends an uplink and returns the downlink received if any.
+	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
+	// Signal returns the signal quality of the last packet received.
+	Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*SignalResponse, error)
+	// RadioStatus returns the radio statistics.
+	RadioStatus(ctx context.Context, in *RadioStatusRequest, opts ...grpc.CallOption) (*RadioStatusResponse, error)
+	// GetRfConfig returns the LoraP2P RF parameters.
+	GetRfConfig(ctx context.Context, in *GetRfConfigRequest, opts ...grpc.CallOption) (*RfConfig, error)
+	// SetRfConfig sets the LoraP2P RF parameters.
+	SetRfConfig(ctx context.Context, in *SetRfConfigRequest, opts ...grpc.CallOption) (*SetRfConfigResponse, error)
+	// StartP2P switches the module into LoraP2P mode and starts receiving,
+	// the LoRaWAN RPCs fail until StopP2P.
+	StartP2P(ctx context.Context, in *StartP2PRequest, opts ...grpc.CallOption) (*StartP2PResponse, error)
+	// StopP2P stops the LoraP2P mode.
+	StopP2P(ctx context.Context, in *StopP2PRequest, opts ...grpc.CallOption) (*StopP2PResponse, error)
+	// P2PTransmit sends a LoraP2P frame.
+	P2PTransmit(ctx context.Context, in *P2PTransmitRequest, opts ...grpc.CallOption) (*P2PTransmitResponse, error)
+	// P2PReceive streams the LoraP2P frames received.
+	P2PReceive(ctx context.Context, in *P2PReceiveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Frame], error)
+	// Events streams the at+recv notifications of the module.
+	Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type loraClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLoraClient(cc grpc.ClientConnInterface) LoraClient {
+	return &loraClient{cc}
+}
+
+func (c *loraClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionResponse)
+	err := c.cc.Invoke(ctx, Lora_Version_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, Lora_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetConfigResponse)
+	err := c.cc.Invoke(ctx, Lora_SetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) Join(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*JoinResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JoinResponse)
+	err := c.cc.Invoke(ctx, Lora_Join_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendResponse)
+	err := c.cc.Invoke(ctx, Lora_Send_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*SignalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignalResponse)
+	err := c.cc.Invoke(ctx, Lora_Signal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) RadioStatus(ctx context.Context, in *RadioStatusRequest, opts ...grpc.CallOption) (*RadioStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RadioStatusResponse)
+	err := c.cc.Invoke(ctx, Lora_RadioStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) GetRfConfig(ctx context.Context, in *GetRfConfigRequest, opts ...grpc.CallOption) (*RfConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RfConfig)
+	err := c.cc.Invoke(ctx, Lora_GetRfConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) SetRfConfig(ctx context.Context, in *SetRfConfigRequest, opts ...grpc.CallOption) (*SetRfConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetRfConfigResponse)
+	err := c.cc.Invoke(ctx, Lora_SetRfConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) StartP2P(ctx context.Context, in *StartP2PRequest, opts ...grpc.CallOption) (*StartP2PResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartP2PResponse)
+	err := c.cc.Invoke(ctx, Lora_StartP2P_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) StopP2P(ctx context.Context, in *StopP2PRequest, opts ...grpc.CallOption) (*StopP2PResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopP2PResponse)
+	err := c.cc.Invoke(ctx, Lora_StopP2P_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) P2PTransmit(ctx context.Context, in *P2PTransmitRequest, opts ...grpc.CallOption) (*P2PTransmitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(P2PTransmitResponse)
+	err := c.cc.Invoke(ctx, Lora_P2PTransmit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loraClient) P2PReceive(ctx context.Context, in *P2PReceiveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Frame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Lora_ServiceDesc.Streams[0], Lora_P2PReceive_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[P2PReceiveRequest, Frame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lora_P2PReceiveClient = grpc.ServerStreamingClient[Frame]
+
+func (c *loraClient) Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Lora_ServiceDesc.Streams[1], Lora_Events_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lora_EventsClient = grpc.ServerStreamingClient[Event]
+
+// LoraServer is the server API for Lora service.
+// All implementations must embed UnimplementedLoraServer
+// for forward compatibility.
+//
+// Lora controls the RAK811 modules of a server. Every request names the
+// module with device, it can be left empty when the server has a single
+// module.
+//
+// Errors reported by a module carry an ErrorInfo detail with the domain
+// rak811, the reason MODULE_ERROR and the module error code in the code
+// metadata.
+type LoraServer interface {
+	// Version returns the firmware version.
+	Version(context.Context, *VersionRequest) (*VersionResponse, error)
+	// GetConfig reads LoRaWAN configuration values.
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	// SetConfig writes LoRaWAN configuration values.
+	SetConfig(context.Context, *SetConfigRequest) (*SetConfigResponse, error)
+	// Join joins the LoRaWAN network.
+	Join(context.Context, *JoinRequest) (*JoinResponse, error)
+	// Send sends an uplink and returns the downlink received if any.
+	Send(context.Context, *SendRequest) (*SendResponse, error)
+	// Signal returns the signal quality of the last packet received.
+	Signal(context.Context, *SignalRequest) (*SignalResponse, error)
+	// RadioStatus returns the radio statistics.
+	RadioStatus(context.Context, *RadioStatusRequest) (*RadioStatusResponse, error)
+	// GetRfConfig returns the LoraP2P RF parameters.
+	GetRfConfig(context.Context, *GetRfConfigRequest) (*RfConfig, error)
+	// SetRfConfig sets the LoraP2P RF parameters.
+	SetRfConfig(context.Context, *SetRfConfigRequest) (*SetRfConfigResponse, error)
+	// StartP2P switches the module into LoraP2P mode and starts receiving,
+	// the LoRaWAN RPCs fail until StopP2P.
+	StartP2P(context.Context, *StartP2PRequest) (*StartP2PResponse, error)
+	// StopP2P stops the LoraP2P mode.
+	StopP2P(context.Context, *StopP2PRequest) (*StopP2PResponse, error)
+	// P2PTransmit sends a LoraP2P frame.
+	P2PTransmit(context.Context, *P2PTransmitRequest) (*P2PTransmitResponse, error)
+	// P2PReceive streams the LoraP2P frames received.
+	P2PReceive(*P2PReceiveRequest, grpc.ServerStreamingServer[Frame]) error
+	// Events streams the at+recv notifications of the module.
+	Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedLoraServer()
+}
+
+// UnimplementedLoraServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLoraServer struct{}
+
+func (UnimplementedLoraServer) Version(context.Context, *VersionRequest) (*VersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedLoraServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedLoraServer) SetConfig(context.Context, *SetConfigRequest) (*SetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfig not implemented")
+}
+func (UnimplementedLoraServer) Join(context.Context, *JoinRequest) (*JoinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Join not implemented")
+}
+func (UnimplementedLoraServer) Send(context.Context, *SendRequest) (*SendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Send not implemented")
+}
+func (UnimplementedLoraServer) Signal(context.Context, *SignalRequest) (*SignalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Signal not implemented")
+}
+func (UnimplementedLoraServer) RadioStatus(context.Context, *RadioStatusRequest) (*RadioStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RadioStatus not implemented")
+}
+func (UnimplementedLoraServer) GetRfConfig(context.Context, *GetRfConfigRequest) (*RfConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRfConfig not implemented")
+}
+func (UnimplementedLoraServer) SetRfConfig(context.Context, *SetRfConfigRequest) (*SetRfConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRfConfig not implemented")
+}
+func (UnimplementedLoraServer) StartP2P(context.Context, *StartP2PRequest) (*StartP2PResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartP2P not implemented")
+}
+func (UnimplementedLoraServer) StopP2P(context.Context, *StopP2PRequest) (*StopP2PResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopP2P not implemented")
+}
+func (UnimplementedLoraServer) P2PTransmit(context.Context, *P2PTransmitRequest) (*P2PTransmitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method P2PTransmit not implemented")
+}
+func (UnimplementedLoraServer) P2PReceive(*P2PReceiveRequest, grpc.ServerStreamingServer[Frame]) error {
+	return status.Errorf(codes.Unimplemented, "method P2PReceive not implemented")
+}
+func (UnimplementedLoraServer) Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Events not implemented")
+}
+func (UnimplementedLoraServer) mustEmbedUnimplementedLoraServer() {}
+func (UnimplementedLoraServer) testEmbeddedByValue()              {}
+
+// UnsafeLoraServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LoraServer will
+// result in compilation errors.
+type UnsafeLoraServer interface {
+	mustEmbedUnimplementedLoraServer()
+}
+
+func RegisterLoraServer(s grpc.ServiceRegistrar, srv LoraServer) {
+	// If the following call pancis, it indicates UnimplementedLoraServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Lora_ServiceDesc, srv)
+}
+
+func _Lora_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_Version_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).Version(ctx, req.(*VersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_SetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).SetConfig(ctx, req.(*SetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_Join_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).Join(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_Join_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).Join(ctx, req.(*JoinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_Send_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).Send(ctx, req.(*SendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_Signal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).Signal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_Signal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).Signal(ctx, req.(*SignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_RadioStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RadioStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).RadioStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_RadioStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).RadioStatus(ctx, req.(*RadioStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_GetRfConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRfConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).GetRfConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_GetRfConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).GetRfConfig(ctx, req.(*GetRfConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_SetRfConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRfConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).SetRfConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_SetRfConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).SetRfConfig(ctx, req.(*SetRfConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_StartP2P_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartP2PRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).StartP2P(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_StartP2P_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).StartP2P(ctx, req.(*StartP2PRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_StopP2P_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopP2PRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).StopP2P(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_StopP2P_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).StopP2P(ctx, req.(*StopP2PRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_P2PTransmit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(P2PTransmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoraServer).P2PTransmit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lora_P2PTransmit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoraServer).P2PTransmit(ctx, req.(*P2PTransmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lora_P2PReceive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(P2PReceiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LoraServer).P2PReceive(m, &grpc.GenericServerStream[P2PReceiveRequest, Frame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lora_P2PReceiveServer = grpc.ServerStreamingServer[Frame]
+
+func _Lora_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LoraServer).Events(m, &grpc.GenericServerStream[EventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lora_EventsServer = grpc.ServerStreamingServer[Event]
+
+// Lora_ServiceDesc is the grpc.ServiceDesc for Lora service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Lora_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rak811.v1.Lora",
+	HandlerType: (*LoraServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Version",
+			Handler:    _Lora_Version_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _Lora_GetConfig_Handler,
+		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _Lora_SetConfig_Handler,
+		},
+		{
+			MethodName: "Join",
+			Handler:    _Lora_Join_Handler,
+		},
+		{
+			MethodName: "Send",
+			Handler:    _Lora_Send_Handler,
+		},
+		{
+			MethodName: "Signal",
+			Handler:    _Lora_Signal_Handler,
+		},
+		{
+			MethodName: "RadioStatus",
+			Handler:    _Lora_RadioStatus_Handler,
+		},
+		{
+			MethodName: "GetRfConfig",
+			Handler:    _Lora_GetRfConfig_Handler,
+		},
+		{
+			MethodName: "SetRfConfig",
+			Handler:    _Lora_SetRfConfig_Handler,
+		},
+		{
+			MethodName: "StartP2P",
+			Handler:    _Lora_StartP2P_Handler,
+		},
+		{
+			MethodName: "StopP2P",
+			Handler:    _Lora_StopP2P_Handler,
+		},
+		{
+			MethodName: "P2PTransmit",
+			Handler:    _Lora_P2PTransmit_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "P2PReceive",
+			Handler:       _Lora_P2PReceive_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Events",
+			Handler:       _Lora_Events_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rak811.proto",
+}
@@ -0,0 +1,690 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/calvernaz/rak811"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain and ErrorReason of the ErrorInfo detail of module errors.
+const (
+	ErrorDomain = "rak811"
+	ErrorReason = "MODULE_ERROR"
+)
+
+// streamBacklog events waiting to be sent to a stream, the others are
+// dropped.
+const streamBacklog = 16
+
+// Server implements the Lora service for the modules added to it.
+type Server struct {
+	UnimplementedLoraServer
+
+	mu      sync.Mutex
+	devices map[string]*device
+}
+
+// NewServer creates a server without modules.
+func NewServer() *Server {
+	return &Server{devices: make(map[string]*device)}
+}
+
+// AddDevice serves the module under name, replacing the module already
+// added under that name.
+func (s *Server) AddDevice(name string, l *rak811.Lora) {
+	d := &device{
+		name:   name,
+		lora:   l,
+		events: newBroker[*Event](),
+		frames: newBroker[*Frame](),
+	}
+
+	s.mu.Lock()
+	old := s.devices[name]
+	s.devices[name] = d
+	s.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+// RemoveDevice stops serving the module added under name, its streams
+// end. The module is left open.
+func (s *Server) RemoveDevice(name string) {
+	s.mu.Lock()
+	d := s.devices[name]
+	delete(s.devices, name)
+	s.mu.Unlock()
+
+	if d != nil {
+		d.close()
+	}
+}
+
+// device returns the module named in a request, the only one when the
+// name is empty.
+func (s *Server) device(name string) (*device, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == "" && len(s.devices) == 1 {
+		for _, d := range s.devices {
+			return d, nil
+		}
+	}
+	d, ok := s.devices[name]
+	if !ok {
+		if name == "" {
+			return nil, status.Error(codes.InvalidArgument, "device required")
+		}
+		return nil, status.Errorf(codes.NotFound, "unknown device %q", name)
+	}
+	return d, nil
+}
+
+func (s *Server) Version(ctx context.Context, req *VersionRequest) (*VersionResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.do(ctx, (*rak811.Lora).Version)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &VersionResponse{Version: strings.TrimPrefix(resp, rak811.OK)}, nil
+}
+
+func (s *Server) GetConfig(ctx context.Context, req *GetConfigRequest) (*GetConfigResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetKeys()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "keys required")
+	}
+	for _, key := range req.GetKeys() {
+		if err := rak811.ValidateConfigKey(key); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if rak811.IsSecretConfigKey(key) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s can't be read", key)
+		}
+	}
+
+	config := make(map[string]string, len(req.GetKeys()))
+	for _, key := range req.GetKeys() {
+		resp, err := d.do(ctx, func(l *rak811.Lora) (string, error) {
+			return l.GetConfig(key)
+		})
+		if err != nil {
+			return nil, statusError(err)
+		}
+		config[key] = strings.TrimPrefix(resp, rak811.OK)
+	}
+	return &GetConfigResponse{Config: config}, nil
+}
+
+func (s *Server) SetConfig(ctx context.Context, req *SetConfigRequest) (*SetConfigResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetConfig()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "config required")
+	}
+
+	keys := make([]string, 0, len(req.GetConfig()))
+	for key, value := range req.GetConfig() {
+		if err := rak811.ValidateConfigKey(key); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := rak811.ValidateConfigValue(value); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + ":" + req.GetConfig()[key]
+	}
+	if _, err := d.do(ctx, func(l *rak811.Lora) (string, error) {
+		return l.SetConfig(strings.Join(pairs, "&"))
+	}); err != nil {
+		return nil, statusError(err)
+	}
+	return &SetConfigResponse{}, nil
+}
+
+func (s *Server) Join(ctx context.Context, req *JoinRequest) (*JoinResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+
+	join := (*rak811.Lora).JoinOTAA
+	if req.GetMode() == JoinMode_JOIN_MODE_ABP {
+		join = (*rak811.Lora).JoinABP
+	}
+	resp, err := d.do(ctx, join)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	d.event(resp)
+
+	switch {
+	case resp == rak811.JoinSuccess, req.GetMode() == JoinMode_JOIN_MODE_ABP && strings.HasPrefix(resp, rak811.OK):
+		return &JoinResponse{Result: JoinResult_JOIN_RESULT_SUCCESS}, nil
+	case resp == rak811.JoinFail:
+		return &JoinResponse{Result: JoinResult_JOIN_RESULT_FAILED}, nil
+	case resp == rak811.JoinTimeout:
+		return &JoinResponse{Result: JoinResult_JOIN_RESULT_TIMEOUT}, nil
+	}
+	return nil, status.Errorf(codes.Internal, "unexpected join response %q", resp)
+}
+
+func (s *Server) Send(ctx context.Context, req *SendRequest) (*SendResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPort() < 1 || req.GetPort() > 223 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", req.GetPort())
+	}
+	if len(req.GetPayload()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, rak811.ErrEmptyPayload.Error())
+	}
+
+	confirmed := 0
+	if req.GetConfirmed() {
+		confirmed = 1
+	}
+	resp, err := d.do(ctx, func(l *rak811.Lora) (string, error) {
+		return l.Send(fmt.Sprintf("%d,%d,%X", confirmed, req.GetPort(), req.GetPayload()))
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	evt := d.event(resp)
+	if evt == nil {
+		return nil, status.Errorf(codes.Internal, "unexpected response %q", resp)
+	}
+
+	res := &SendResponse{Status: UplinkStatus_UPLINK_STATUS_UNCONFIRMED}
+	if req.GetConfirmed() {
+		res.Status = UplinkStatus_UPLINK_STATUS_CONFIRMED
+	}
+	switch evt.GetStatus() {
+	case rak811.StatusTxConfirmed, rak811.StatusTxUnconfirmed:
+	case rak811.StatusRecvData:
+		res.Downlink = evt.GetFrame()
+	case rak811.StatusTxTimeout:
+		res.Status = UplinkStatus_UPLINK_STATUS_TIMEOUT
+	default:
+		return nil, status.Error(codes.Internal, evt.GetDescription())
+	}
+	return res, nil
+}
+
+func (s *Server) Signal(ctx context.Context, req *SignalRequest) (*SignalResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.do(ctx, (*rak811.Lora).Signal)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	sig, err := rak811.ParseSignal(resp)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &SignalResponse{Rssi: int32(sig.RSSI), Snr: int32(sig.SNR)}, nil
+}
+
+func (s *Server) RadioStatus(ctx context.Context, req *RadioStatusRequest) (*RadioStatusResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.do(ctx, (*rak811.Lora).GetRadioStatus)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	st, err := rak811.ParseRadioStatus(resp)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &RadioStatusResponse{
+		TxSuccess: int32(st.TxSuccess),
+		TxError:   int32(st.TxError),
+		RxSuccess: int32(st.RxSuccess),
+		RxTimeout: int32(st.RxTimeout),
+		RxError:   int32(st.RxError),
+		Rssi:      int32(st.RSSI),
+		Snr:       int32(st.SNR),
+	}, nil
+}
+
+func (s *Server) GetRfConfig(ctx context.Context, req *GetRfConfigRequest) (*RfConfig, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if d.p2p != nil {
+		conf := d.p2p.Config()
+		d.mu.Unlock()
+		return toRfConfig(conf), nil
+	}
+	d.mu.Unlock()
+
+	resp, err := d.do(ctx, (*rak811.Lora).GetRfConfig)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	conf, err := rak811.ParseRFConfig(resp)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toRfConfig(conf), nil
+}
+
+func (s *Server) SetRfConfig(ctx context.Context, req *SetRfConfigRequest) (*SetRfConfigResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetConfig() == nil {
+		return nil, status.Error(codes.InvalidArgument, "config required")
+	}
+	conf := fromRfConfig(req.GetConfig())
+	if err := conf.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if _, err := d.do(ctx, func(l *rak811.Lora) (string, error) {
+		return l.SetRfConfig(conf.String())
+	}); err != nil {
+		return nil, statusError(err)
+	}
+	return &SetRfConfigResponse{}, nil
+}
+
+func (s *Server) StartP2P(ctx context.Context, req *StartP2PRequest) (*StartP2PResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+
+	conf := rak811.DefaultRFConfig
+	if req.GetConfig() != nil {
+		conf = fromRfConfig(req.GetConfig())
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := d.startP2P(conf); err != nil {
+		return nil, statusError(err)
+	}
+	return &StartP2PResponse{}, nil
+}
+
+func (s *Server) StopP2P(ctx context.Context, req *StopP2PRequest) (*StopP2PResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+	if err := d.stopP2P(); err != nil {
+		return nil, statusError(err)
+	}
+	return &StopP2PResponse{}, nil
+}
+
+func (s *Server) P2PTransmit(ctx context.Context, req *P2PTransmitRequest) (*P2PTransmitResponse, error) {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	p2p := d.p2p
+	d.mu.Unlock()
+	if p2p == nil {
+		return nil, status.Error(codes.FailedPrecondition, "module not in p2p mode")
+	}
+
+	if err := p2p.Transmit(ctx, req.GetPayload()); err != nil {
+		return nil, statusError(err)
+	}
+	return &P2PTransmitResponse{}, nil
+}
+
+func (s *Server) P2PReceive(req *P2PReceiveRequest, stream Lora_P2PReceiveServer) error {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return err
+	}
+
+	frames, cancel := d.frames.subscribe()
+	defer cancel()
+
+	// the client knows the stream is subscribed once it gets the headers
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case f, ok := <-frames:
+			if !ok {
+				return status.Error(codes.Unavailable, "device removed")
+			}
+			if err := stream.Send(f); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *Server) Events(req *EventsRequest, stream Lora_EventsServer) error {
+	d, err := s.device(req.GetDevice())
+	if err != nil {
+		return err
+	}
+
+	events, cancel := d.events.subscribe()
+	defer cancel()
+
+	// the client knows the stream is subscribed once it gets the headers
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "device removed")
+			}
+			if err := stream.Send(evt); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// errP2P the LoRaWAN commands are refused in LoraP2P mode.
+var errP2P = status.Error(codes.FailedPrecondition, "module in p2p mode")
+
+// device a module served, its commands are serialized.
+type device struct {
+	name string
+
+	mu      sync.Mutex
+	lora    *rak811.Lora
+	p2p     *rak811.P2P
+	p2pDone chan struct{}
+
+	events *broker[*Event]
+	frames *broker[*Frame]
+}
+
+// do runs a module command, traced as part of the call of ctx. An
+// ERROR-n reply is returned as the error.
+func (d *device) do(ctx context.Context, fn func(l *rak811.Lora) (string, error)) (string, error) {
+	var resp string
+	d.mu.Lock()
+	if d.p2p != nil {
+		d.mu.Unlock()
+		return "", errP2P
+	}
+	err := d.lora.WithContext(ctx, func(l *rak811.Lora) (err error) {
+		resp, err = fn(l)
+		return err
+	})
+	d.mu.Unlock()
+
+	if err == nil && rak811.WhichError(resp) != nil {
+		return "", errors.New(resp)
+	}
+	return resp, err
+}
+
+// event publishes the at+recv notification of a response, nil if it
+// isn't one.
+func (d *device) event(resp string) *Event {
+	evt := rak811.WhichEventResponse(resp)
+	if evt == nil {
+		return nil
+	}
+
+	e := &Event{
+		Device:      d.name,
+		Status:      int32(evt.Code()),
+		Description: evt.Description(),
+	}
+	if evt.Code() == rak811.StatusRecvData {
+		if f, err := rak811.ParseFrame(resp); err == nil {
+			e.Frame = toFrame(f)
+		}
+	}
+	d.events.publish(e)
+	return e
+}
+
+func (d *device) startP2P(conf rak811.RFConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.p2p != nil {
+		return errP2P
+	}
+	p2p, err := rak811.NewP2P(d.lora, conf)
+	if err != nil {
+		return err
+	}
+	d.p2p = p2p
+	d.p2pDone = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		for f := range p2p.Receive() {
+			frame := toFrame(&f)
+			d.frames.publish(frame)
+			d.events.publish(&Event{
+				Device:      d.name,
+				Status:      rak811.StatusRecvData,
+				Description: rak811.WhichEventResponse("at+recv=0").Description(),
+				Frame:       frame,
+			})
+		}
+	}(d.p2pDone)
+	return nil
+}
+
+// stopP2P stops the LoraP2P mode and switches the module back to LoRaWAN.
+func (d *device) stopP2P() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.p2p == nil {
+		return status.Error(codes.FailedPrecondition, "module not in p2p mode")
+	}
+	err := d.p2p.Close()
+	<-d.p2pDone
+	d.p2p, d.p2pDone = nil, nil
+	if err != nil {
+		return err
+	}
+
+	_, err = d.lora.SetMode(rak811.ModeLoRaWAN)
+	return err
+}
+
+// close ends the streams of a device no longer served.
+func (d *device) close() {
+	d.mu.Lock()
+	if d.p2p != nil {
+		_ = d.p2p.Close()
+		<-d.p2pDone
+		d.p2p, d.p2pDone = nil, nil
+	}
+	d.mu.Unlock()
+
+	d.events.close()
+	d.frames.close()
+}
+
+// statusError converts a command error to a gRPC status, the module
+// errors carry their code in an ErrorInfo detail.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	switch {
+	case errors.Is(err, rak811.ErrDisconnected), errors.Is(err, rak811.ErrClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, rak811.ErrEmptyPayload), errors.Is(err, rak811.ErrPayloadTooLarge):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	code, ok := rak811.ErrorCode(err)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	c := codes.Internal
+	switch code {
+	case rak811.CodeArgErr, rak811.CodeArgNotFind, rak811.CodeTxLenLimitErr:
+		c = codes.InvalidArgument
+	case rak811.CodeNotJoin, rak811.CodeJoinAbpErr, rak811.CodeJoinOtaaErr:
+		c = codes.FailedPrecondition
+	case rak811.CodeMacBusyErr:
+		c = codes.Unavailable
+	}
+
+	msg := err.Error()
+	if lerr := rak811.WhichError(msg); lerr != nil && lerr.Error() != "" {
+		msg = lerr.Error()
+	}
+	st, derr := status.New(c, msg).WithDetails(&errdetails.ErrorInfo{
+		Domain:   ErrorDomain,
+		Reason:   ErrorReason,
+		Metadata: map[string]string{"code": strconv.Itoa(code)},
+	})
+	if derr != nil {
+		return status.Error(c, msg)
+	}
+	return st.Err()
+}
+
+func toFrame(f *rak811.Frame) *Frame {
+	return &Frame{
+		Port:    uint32(f.Port),
+		Rssi:    int32(f.RSSI),
+		Snr:     int32(f.SNR),
+		Payload: f.Payload,
+	}
+}
+
+func toRfConfig(c rak811.RFConfig) *RfConfig {
+	return &RfConfig{
+		Frequency:       uint32(c.Frequency),
+		SpreadingFactor: uint32(c.SpreadingFactor),
+		Bandwidth:       uint32(c.Bandwidth),
+		CodingRate:      uint32(c.CodingRate),
+		Preamble:        uint32(c.Preamble),
+		Power:           uint32(c.Power),
+	}
+}
+
+func fromRfConfig(c *RfConfig) rak811.RFConfig {
+	return rak811.RFConfig{
+		Frequency:       int(c.GetFrequency()),
+		SpreadingFactor: int(c.GetSpreadingFactor()),
+		Bandwidth:       rak811.Bandwidth(c.GetBandwidth()),
+		CodingRate:      rak811.CodingRate(c.GetCodingRate()),
+		Preamble:        int(c.GetPreamble()),
+		Power:           int(c.GetPower()),
+	}
+}
+
+// broker fans messages out to the streams, dropping them for the streams
+// too slow to keep up.
+type broker[T any] struct {
+	mu     sync.Mutex
+	subs   map[chan T]struct{}
+	closed bool
+}
+
+func newBroker[T any]() *broker[T] {
+	return &broker[T]{subs: make(map[chan T]struct{})}
+}
+
+// subscribe returns a channel receiving the messages until cancel is
+// called, or closed when the broker is.
+func (b *broker[T]) subscribe() (<-chan T, func()) {
+	ch := make(chan T, streamBacklog)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *broker[T]) publish(v T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- v:
+		default:
+		}
+	}
+}
+
+func (b *broker[T]) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
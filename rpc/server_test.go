@@ -0,0 +1,253 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811"
+	"github.com/calvernaz/rak811/simulator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newLora(t *testing.T, module *simulator.Module) *rak811.Lora {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go module.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	lora, err := rak811.New(&rak811.Config{Name: "tcp://" + ln.Addr().String(), Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(lora.Close)
+	return lora
+}
+
+// newClient serves s over an in-memory connection.
+func newClient(t *testing.T, s *Server) LoraClient {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer()
+	RegisterLoraServer(srv, s)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewLoraClient(conn)
+}
+
+func moduleCode(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() == ErrorReason {
+			return info.GetMetadata()["code"]
+		}
+	}
+	return ""
+}
+
+func TestServer_LoRaWAN(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	module := simulator.New()
+	s := NewServer()
+	s.AddDevice("node", newLora(t, module))
+	client := newClient(t, s)
+
+	v, err := client.Version(ctx, &VersionRequest{})
+	if err != nil || v.GetVersion() != "2.0.3.0" {
+		t.Fatalf("got %v, %v", v, err)
+	}
+
+	if _, err := client.SetConfig(ctx, &SetConfigRequest{Device: "node", Config: map[string]string{"app_eui": "0011", "dev_addr": "2233"}}); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := client.GetConfig(ctx, &GetConfigRequest{Keys: []string{"app_eui", "dev_addr"}})
+	if err != nil || conf.GetConfig()["app_eui"] != "0011" || conf.GetConfig()["dev_addr"] != "2233" {
+		t.Fatalf("got %v, %v", conf, err)
+	}
+
+	// no secrets read back, no command injection through the keys or values
+	n := len(module.Commands())
+	if _, err := client.GetConfig(ctx, &GetConfigRequest{Keys: []string{"app_key"}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got %v", err)
+	}
+	if _, err := client.GetConfig(ctx, &GetConfigRequest{Keys: []string{"dev_eui\r\nat+reset=0"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v", err)
+	}
+	for _, config := range []map[string]string{
+		{"app_eui": "0011\r\nat+reset=0"},
+		{"app_eui\r\n": "0011"},
+		{"app_eui": "0011&dev_eui:00"},
+	} {
+		if _, err := client.SetConfig(ctx, &SetConfigRequest{Config: config}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%q: got %v", config, err)
+		}
+	}
+	if cmds := module.Commands(); len(cmds) != n {
+		t.Errorf("got commands %q", cmds[n:])
+	}
+
+	_, err = client.Send(ctx, &SendRequest{Port: 2, Payload: []byte{1}})
+	if status.Code(err) != codes.FailedPrecondition || moduleCode(err) != "-5" {
+		t.Fatalf("got %v", err)
+	}
+
+	events, err := client.Events(ctx, &EventsRequest{Device: "node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stream is subscribed once the headers are received
+	if _, err := events.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	join, err := client.Join(ctx, &JoinRequest{})
+	if err != nil || join.GetResult() != JoinResult_JOIN_RESULT_SUCCESS {
+		t.Fatalf("got %v, %v", join, err)
+	}
+
+	module.QueueDownlink(simulator.Downlink{Port: 5, RSSI: -80, SNR: 4, Payload: []byte{0xab}})
+	sent, err := client.Send(ctx, &SendRequest{Port: 2, Confirmed: true, Payload: []byte{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.GetStatus() != UplinkStatus_UPLINK_STATUS_CONFIRMED || sent.GetDownlink().GetPort() != 5 || sent.GetDownlink().GetRssi() != -80 {
+		t.Errorf("got %v", sent)
+	}
+
+	for _, want := range []int32{rak811.StatusJoinedSuccess, rak811.StatusRecvData} {
+		evt, err := events.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if evt.GetStatus() != want || evt.GetDevice() != "node" {
+			t.Errorf("got %v, want status %d", evt, want)
+		}
+	}
+
+	sig, err := client.Signal(ctx, &SignalRequest{})
+	if err != nil || sig.GetRssi() != -40 || sig.GetSnr() != 7 {
+		t.Errorf("got %v, %v", sig, err)
+	}
+	if _, err := client.RadioStatus(ctx, &RadioStatusRequest{}); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := client.Version(ctx, &VersionRequest{Device: "other"}); status.Code(err) != codes.NotFound {
+		t.Errorf("got %v", err)
+	}
+	if _, err := client.Send(ctx, &SendRequest{Port: 0, Payload: []byte{1}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v", err)
+	}
+
+	s.RemoveDevice("node")
+	if _, err := events.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestServer_Join(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	module := simulator.New()
+	s := NewServer()
+	s.AddDevice("node", newLora(t, module))
+	client := newClient(t, s)
+
+	module.SetJoinResult(rak811.JoinFail)
+	join, err := client.Join(ctx, &JoinRequest{})
+	if err != nil || join.GetResult() != JoinResult_JOIN_RESULT_FAILED {
+		t.Errorf("got %v, %v", join, err)
+	}
+
+	module.HandleFunc("join", func(args string) []string { return []string{"ERROR-4"} })
+	if _, err := client.Join(ctx, &JoinRequest{}); status.Code(err) != codes.FailedPrecondition || moduleCode(err) != "-4" {
+		t.Errorf("got %v", err)
+	}
+	module.HandleFunc("join", func(args string) []string { return []string{"OK", "at+recv=8,0,0"} })
+	if _, err := client.Join(ctx, &JoinRequest{}); status.Code(err) != codes.Internal {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestServer_P2P(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	module := simulator.New()
+	s := NewServer()
+	s.AddDevice("node", newLora(t, module))
+	client := newClient(t, s)
+
+	rf := &RfConfig{Frequency: 869525000, SpreadingFactor: 7, Bandwidth: 0, CodingRate: 1, Preamble: 8, Power: 14}
+	if _, err := client.StartP2P(ctx, &StartP2PRequest{Config: rf}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Version(ctx, &VersionRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("got %v", err)
+	}
+	got, err := client.GetRfConfig(ctx, &GetRfConfigRequest{})
+	if err != nil || got.GetFrequency() != rf.GetFrequency() || got.GetSpreadingFactor() != 7 {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	frames, err := client.P2PReceive(ctx, &P2PReceiveRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := frames.Header(); err != nil {
+		t.Fatal(err)
+	}
+	module.Emit("at+recv=0,0,-50,6,2:CAFE")
+	f, err := frames.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.GetRssi() != -50 || f.GetSnr() != 6 || string(f.GetPayload()) != "\xca\xfe" {
+		t.Errorf("got %v", f)
+	}
+
+	if _, err := client.P2PTransmit(ctx, &P2PTransmitRequest{Payload: []byte("hi")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.StopP2P(ctx, &StopP2PRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Version(ctx, &VersionRequest{}); err != nil {
+		t.Errorf("got %v", err)
+	}
+
+	cmds := module.Commands()
+	want := []string{"mode=1", "rf_config=869525000,7,0,1,8,14", "rxc=1", "txc=1,0,6869", "rxc=1", "rx_stop", "mode=0", "version"}
+	if len(cmds) != len(want) {
+		t.Fatalf("got %q, want %q", cmds, want)
+	}
+	for i := range want {
+		if cmds[i] != want[i] {
+			t.Fatalf("got %q, want %q", cmds, want)
+		}
+	}
+}
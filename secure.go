@@ -0,0 +1,358 @@
+package rak811
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	// SecureHeaderSize bytes prepended to every frame: key id, sender
+	// and frame counter.
+	SecureHeaderSize = 7
+	// SecureMICSize bytes of the message integrity code appended to every frame.
+	SecureMICSize = 4
+
+	// KeySize size of the AES-128 pre-shared keys.
+	KeySize = 16
+)
+
+var (
+	// ErrNoKey the key isn't in the keyring.
+	ErrNoKey = errors.New("key not found")
+	// ErrCounterExhausted the frame counter can't be incremented anymore,
+	// a new key or sender must be used.
+	ErrCounterExhausted = errors.New("frame counter exhausted")
+)
+
+// sessionKeys keys derived from a pre-shared key, so the same key isn't
+// used for encryption and authentication.
+type sessionKeys struct {
+	enc cipher.Block
+	mac cipher.Block
+}
+
+func deriveKeys(key []byte) (*sessionKeys, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size: %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	derived := make([]byte, 2*aes.BlockSize)
+	derived[0], derived[aes.BlockSize] = 0x01, 0x02
+	block.Encrypt(derived[:aes.BlockSize], derived[:aes.BlockSize])
+	block.Encrypt(derived[aes.BlockSize:], derived[aes.BlockSize:])
+
+	enc, err := aes.NewCipher(derived[:aes.BlockSize])
+	if err != nil {
+		return nil, err
+	}
+	mac, err := aes.NewCipher(derived[aes.BlockSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &sessionKeys{enc: enc, mac: mac}, nil
+}
+
+// Keyring pre-shared keys indexed by key id. Frames are sent with the
+// active key and received with any key in the ring, so keys can be
+// rotated by adding the new key everywhere before activating it.
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[uint8]*sessionKeys
+	active uint8
+	ok     bool
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[uint8]*sessionKeys)}
+}
+
+// Add adds or replaces the AES-128 key with id.
+func (k *Keyring) Add(id uint8, key []byte) error {
+	keys, err := deriveKeys(key)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys[id] = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// Remove removes the key with id, deactivating it if active.
+func (k *Keyring) Remove(id uint8) {
+	k.mu.Lock()
+	delete(k.keys, id)
+	if k.active == id {
+		k.ok = false
+	}
+	k.mu.Unlock()
+}
+
+// Activate selects the key used to send frames.
+func (k *Keyring) Activate(id uint8) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return ErrNoKey
+	}
+	k.active, k.ok = id, true
+	return nil
+}
+
+func (k *Keyring) activeKey() (uint8, *sessionKeys, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if !k.ok {
+		return 0, nil, ErrNoKey
+	}
+	return k.active, k.keys[k.active], nil
+}
+
+func (k *Keyring) key(id uint8) (*sessionKeys, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys, ok := k.keys[id]
+	if !ok {
+		return nil, ErrNoKey
+	}
+	return keys, nil
+}
+
+// SecureConfig security layer settings.
+type SecureConfig struct {
+	Keys *Keyring
+	// Sender identifies the node, it must be unique among the nodes
+	// sharing a key.
+	Sender uint16
+	// Counter last frame counter used by the sender, restore it after a
+	// restart so the receivers don't discard the frames as replays.
+	Counter uint32
+	// LastCounters last frame counter received from every sender, restore
+	// them after a restart from Secure.LastCounters. Otherwise the first
+	// replayed frame of every sender is accepted again after a restart.
+	LastCounters map[uint16]uint32
+}
+
+// Secure encrypts and authenticates the frames of a link with AES-128
+// CTR and CMAC. The frame counter of every sender must increase, frames
+// with a counter already seen are discarded as replays, as well as frames
+// that fail the integrity check. Counter and LastCounters must be
+// persisted and restored across restarts for the replay protection to
+// hold.
+type Secure struct {
+	link   Link
+	keys   *Keyring
+	sender uint16
+
+	frames chan Frame
+
+	mu      sync.Mutex
+	counter uint32
+	last    map[uint16]uint32
+}
+
+// NewSecure starts decrypting the frames received on link.
+func NewSecure(link Link, config SecureConfig) (*Secure, error) {
+	if config.Keys == nil {
+		return nil, errors.New("missing keyring")
+	}
+
+	s := &Secure{
+		link:    link,
+		keys:    config.Keys,
+		sender:  config.Sender,
+		frames:  make(chan Frame, frameBacklog),
+		counter: config.Counter,
+		last:    make(map[uint16]uint32, len(config.LastCounters)),
+	}
+	for sender, counter := range config.LastCounters {
+		s.last[sender] = counter
+	}
+	go s.run()
+	return s, nil
+}
+
+// Counter returns the last frame counter used, to be persisted.
+func (s *Secure) Counter() uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counter
+}
+
+// LastCounters returns the last frame counter received from every sender,
+// to be persisted with Counter and restored with SecureConfig.LastCounters.
+func (s *Secure) LastCounters() map[uint16]uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := make(map[uint16]uint32, len(s.last))
+	for sender, counter := range s.last {
+		last[sender] = counter
+	}
+	return last
+}
+
+// Transmit encrypts the payload with the active key and sends it.
+func (s *Secure) Transmit(ctx context.Context, payload []byte) error {
+	if len(payload) == 0 {
+		return ErrEmptyPayload
+	}
+
+	id, keys, err := s.keys.activeKey()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.counter == math.MaxUint32 {
+		s.mu.Unlock()
+		return ErrCounterExhausted
+	}
+	s.counter++
+	counter := s.counter
+	s.mu.Unlock()
+
+	frame := make([]byte, SecureHeaderSize, SecureHeaderSize+len(payload)+SecureMICSize)
+	frame[0] = id
+	binary.BigEndian.PutUint16(frame[1:3], s.sender)
+	binary.BigEndian.PutUint32(frame[3:7], counter)
+
+	ciphertext := make([]byte, len(payload))
+	cipher.NewCTR(keys.enc, secureIV(frame[:SecureHeaderSize])).XORKeyStream(ciphertext, payload)
+	frame = append(frame, ciphertext...)
+	frame = append(frame, cmac(keys.mac, frame)[:SecureMICSize]...)
+
+	return s.link.Transmit(ctx, frame)
+}
+
+// Receive returns the channel where the authenticated and decrypted
+// frames are delivered. The channel is closed when the underlying link
+// receive channel is closed.
+func (s *Secure) Receive() <-chan Frame {
+	return s.frames
+}
+
+func (s *Secure) run() {
+	defer close(s.frames)
+
+	for frame := range s.link.Receive() {
+		payload, err := s.open(frame.Payload)
+		if err != nil {
+			continue
+		}
+		frame.Payload = payload
+
+		select {
+		case s.frames <- frame:
+		default:
+		}
+	}
+}
+
+func (s *Secure) open(frame []byte) ([]byte, error) {
+	if len(frame) <= SecureHeaderSize+SecureMICSize {
+		return nil, errors.New("frame too short")
+	}
+
+	keys, err := s.keys.key(frame[0])
+	if err != nil {
+		return nil, err
+	}
+
+	body, mic := frame[:len(frame)-SecureMICSize], frame[len(frame)-SecureMICSize:]
+	if subtle.ConstantTimeCompare(cmac(keys.mac, body)[:SecureMICSize], mic) != 1 {
+		return nil, errors.New("invalid mic")
+	}
+
+	sender := binary.BigEndian.Uint16(frame[1:3])
+	counter := binary.BigEndian.Uint32(frame[3:7])
+
+	s.mu.Lock()
+	if last, ok := s.last[sender]; ok && counter <= last {
+		s.mu.Unlock()
+		return nil, errors.New("replayed frame")
+	}
+	s.last[sender] = counter
+	s.mu.Unlock()
+
+	payload := make([]byte, len(body)-SecureHeaderSize)
+	cipher.NewCTR(keys.enc, secureIV(frame[:SecureHeaderSize])).XORKeyStream(payload, body[SecureHeaderSize:])
+	return payload, nil
+}
+
+// secureIV builds the CTR initial block from the frame header, unique
+// for every key, sender and counter.
+func secureIV(header []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	iv[0] = 0x01
+	copy(iv[1:], header)
+	return iv
+}
+
+// cmac AES-CMAC as specified by RFC 4493.
+func cmac(block cipher.Block, msg []byte) []byte {
+	const rb = 0x87
+
+	k1 := make([]byte, aes.BlockSize)
+	block.Encrypt(k1, k1)
+	shift := func(in []byte) []byte {
+		out := make([]byte, aes.BlockSize)
+		for i := 0; i < aes.BlockSize-1; i++ {
+			out[i] = in[i]<<1 | in[i+1]>>7
+		}
+		out[aes.BlockSize-1] = in[aes.BlockSize-1] << 1
+		if in[0]&0x80 != 0 {
+			out[aes.BlockSize-1] ^= rb
+		}
+		return out
+	}
+	k1 = shift(k1)
+	k2 := shift(k1)
+
+	n := (len(msg) + aes.BlockSize - 1) / aes.BlockSize
+	complete := n > 0 && len(msg)%aes.BlockSize == 0
+	if n == 0 {
+		n = 1
+	}
+
+	last := make([]byte, aes.BlockSize)
+	copy(last, msg[(n-1)*aes.BlockSize:])
+	if complete {
+		xorBytes(last, last, k1)
+	} else {
+		last[len(msg)-(n-1)*aes.BlockSize] = 0x80
+		xorBytes(last, last, k2)
+	}
+
+	x := make([]byte, aes.BlockSize)
+	for i := 0; i < n-1; i++ {
+		xorBytes(x, x, msg[i*aes.BlockSize:(i+1)*aes.BlockSize])
+		block.Encrypt(x, x)
+	}
+	xorBytes(x, x, last)
+	block.Encrypt(x, x)
+	return x
+}
+
+func xorBytes(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
@@ -0,0 +1,152 @@
+package rak811
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestCMAC(t *testing.T) {
+	// RFC 4493 test vectors
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	msg, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411")
+	tests := []struct {
+		len int
+		mac string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+	}
+
+	block, _ := aes.NewCipher(key)
+	for _, tt := range tests {
+		actual := hex.EncodeToString(cmac(block, msg[:tt.len]))
+		if actual != tt.mac {
+			t.Errorf("len %d: got %s, want %s", tt.len, actual, tt.mac)
+		}
+	}
+}
+
+func TestSecure_Transmit(t *testing.T) {
+	keys := NewKeyring()
+	if err := keys.Add(1, bytes.Repeat([]byte{0x11}, KeySize)); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if err := keys.Activate(1); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	medium := newMemMedium(nil)
+	spy := medium.Link()
+	a, _ := NewSecure(medium.Link(), SecureConfig{Keys: keys, Sender: 1})
+	b, _ := NewSecure(medium.Link(), SecureConfig{Keys: keys, Sender: 2})
+
+	if err := a.Transmit(context.Background(), []byte("open gate 3")); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	frame := receiveFrame(t, b.Receive())
+	if string(frame.Payload) != "open gate 3" {
+		t.Errorf("got %q, want %q", frame.Payload, "open gate 3")
+	}
+
+	captured := receiveFrame(t, spy.Receive())
+	if bytes.Contains(captured.Payload, []byte("open gate")) {
+		t.Error("payload sent in clear text")
+	}
+	if a.Counter() != 1 {
+		t.Errorf("got counter %d, want 1", a.Counter())
+	}
+
+	// replayed and tampered frames are discarded
+	_ = spy.Transmit(context.Background(), captured.Payload)
+	tampered := append([]byte(nil), captured.Payload...)
+	tampered[SecureHeaderSize] ^= 0x01
+	tampered[6]++
+	_ = spy.Transmit(context.Background(), tampered)
+
+	select {
+	case frame := <-b.Receive():
+		t.Errorf("got unexpected %q", frame.Payload)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSecure_LastCounters(t *testing.T) {
+	keys := NewKeyring()
+	_ = keys.Add(1, bytes.Repeat([]byte{0x11}, KeySize))
+	_ = keys.Activate(1)
+
+	medium := newMemMedium(nil)
+	spy := medium.Link()
+	a, _ := NewSecure(medium.Link(), SecureConfig{Keys: keys, Sender: 1})
+	b, _ := NewSecure(medium.Link(), SecureConfig{Keys: keys, Sender: 2})
+
+	_ = a.Transmit(context.Background(), []byte("open gate 3"))
+	receiveFrame(t, b.Receive())
+	captured := receiveFrame(t, spy.Receive())
+
+	last := b.LastCounters()
+	if len(last) != 1 || last[1] != 1 {
+		t.Fatalf("got %v", last)
+	}
+
+	// the receiver restarts with the counters persisted
+	restarted, _ := NewSecure(medium.Link(), SecureConfig{Keys: keys, Sender: 2, LastCounters: last})
+	_ = spy.Transmit(context.Background(), captured.Payload)
+	select {
+	case frame := <-restarted.Receive():
+		t.Errorf("got replayed %q", frame.Payload)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_ = a.Transmit(context.Background(), []byte("open gate 4"))
+	if frame := receiveFrame(t, restarted.Receive()); string(frame.Payload) != "open gate 4" {
+		t.Errorf("got %q, want %q", frame.Payload, "open gate 4")
+	}
+}
+
+func TestSecure_KeyRotation(t *testing.T) {
+	old, current := NewKeyring(), NewKeyring()
+	_ = old.Add(1, bytes.Repeat([]byte{0x11}, KeySize))
+	_ = old.Activate(1)
+	_ = current.Add(1, bytes.Repeat([]byte{0x11}, KeySize))
+	_ = current.Add(2, bytes.Repeat([]byte{0x22}, KeySize))
+	_ = current.Activate(2)
+
+	medium := newMemMedium(nil)
+	a, _ := NewSecure(medium.Link(), SecureConfig{Keys: old, Sender: 1})
+	b, _ := NewSecure(medium.Link(), SecureConfig{Keys: current, Sender: 2})
+
+	_ = a.Transmit(context.Background(), []byte("old key"))
+	if frame := receiveFrame(t, b.Receive()); string(frame.Payload) != "old key" {
+		t.Errorf("got %q, want %q", frame.Payload, "old key")
+	}
+
+	_ = b.Transmit(context.Background(), []byte("new key"))
+	select {
+	case frame := <-a.Receive():
+		t.Errorf("got %q with a key not in the ring", frame.Payload)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	current.Remove(2)
+	if err := b.Transmit(context.Background(), []byte("no key")); err != ErrNoKey {
+		t.Errorf("got %v, want %v", err, ErrNoKey)
+	}
+}
+
+func receiveFrame(t *testing.T, frames <-chan Frame) Frame {
+	t.Helper()
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+	return Frame{}
+}
@@ -0,0 +1,482 @@
+// Package simulator emulates a RAK811 module answering AT commands, so
+// code using the rak811 package can be tested without hardware.
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+const crlf = "\r\n"
+
+// Downlink data delivered with the response of the next uplink.
+type Downlink struct {
+	Port    uint8
+	RSSI    int
+	SNR     int
+	Payload []byte
+}
+
+// Module simulated RAK811 module. Every port opened on the module shares
+// its state, and the unsolicited events are sent to all of them.
+type Module struct {
+	mu        sync.Mutex
+	version   string
+	mode      int
+	band      string
+	dr        string
+	rfConfig  string
+	config    map[string]string
+	channels  []bool
+	joined    bool
+	join      string
+	downlinks []Downlink
+	commands  []string
+	handlers  map[string]func(args string) []string
+	sessions  map[sink]struct{}
+}
+
+// sink receives the lines sent by the module.
+type sink interface {
+	write(lines []string) error
+}
+
+// New creates a module with the factory defaults.
+func New() *Module {
+	return &Module{
+		version:  "2.0.3.0",
+		band:     "EU868",
+		dr:       "5",
+		rfConfig: "868100000,12,0,1,8,20",
+		config: map[string]string{
+			"dev_eui":  "0102030405060708",
+			"app_eui":  "0000000000000000",
+			"app_key":  "00000000000000000000000000000000",
+			"dev_addr": "00000000",
+			"class":    "0",
+		},
+		join:     "at+recv=3,0,0",
+		handlers: make(map[string]func(args string) []string),
+		sessions: make(map[sink]struct{}),
+	}
+}
+
+// SetConfig sets a LoRaWAN configuration value, as read by get_config.
+func (m *Module) SetConfig(key, value string) {
+	m.mu.Lock()
+	m.config[key] = value
+	m.mu.Unlock()
+}
+
+// SetJoinResult sets the event reported after join, at+recv=3,0,0 by default.
+func (m *Module) SetJoinResult(event string) {
+	m.mu.Lock()
+	m.join = event
+	m.mu.Unlock()
+}
+
+// QueueDownlink delivers the downlink with the response of the next uplink.
+func (m *Module) QueueDownlink(d Downlink) {
+	m.mu.Lock()
+	m.downlinks = append(m.downlinks, d)
+	m.mu.Unlock()
+}
+
+// HandleFunc overrides the response to a command, identified by the
+// text before the = sign, e.g. "send" or "version".
+func (m *Module) HandleFunc(command string, fn func(args string) []string) {
+	m.mu.Lock()
+	m.handlers[command] = fn
+	m.mu.Unlock()
+}
+
+// Commands returns the commands received, without the at+ prefix.
+func (m *Module) Commands() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.commands...)
+}
+
+// Emit sends an unsolicited line, such as an at+recv event, to every port.
+func (m *Module) Emit(line string) {
+	m.mu.Lock()
+	sessions := make([]sink, 0, len(m.sessions))
+	for s := range m.sessions {
+		sessions = append(sessions, s)
+	}
+	m.mu.Unlock()
+
+	for _, s := range sessions {
+		_ = s.write([]string{line})
+	}
+}
+
+// Port returns an in-memory serial port connected to the module. Reads
+// block until the module has something to say or the port is closed.
+func (m *Module) Port() io.ReadWriteCloser {
+	p := &port{module: m}
+	p.cond = sync.NewCond(&p.mu)
+
+	m.mu.Lock()
+	m.sessions[p] = struct{}{}
+	m.mu.Unlock()
+
+	return p
+}
+
+// Serve accepts connections on l, each one behaving as a raw serial
+// port, until l is closed.
+func (m *Module) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go m.ServeConn(conn)
+	}
+}
+
+// ServeConn answers the commands read from conn until it is closed.
+func (m *Module) ServeConn(conn io.ReadWriteCloser) {
+	s := &session{conn: conn}
+
+	m.mu.Lock()
+	m.sessions[s] = struct{}{}
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.sessions, s)
+		m.mu.Unlock()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := s.write(m.Handle(line)); err != nil {
+			return
+		}
+	}
+}
+
+// Handle processes a command line and returns the response lines.
+func (m *Module) Handle(line string) []string {
+	if !strings.HasPrefix(line, "at+") {
+		return []string{"ERROR-1"}
+	}
+	cmd := strings.TrimPrefix(line, "at+")
+
+	name, args := cmd, ""
+	if i := strings.IndexByte(cmd, '='); i >= 0 {
+		name, args = cmd[:i], cmd[i+1:]
+	}
+
+	m.mu.Lock()
+	m.commands = append(m.commands, cmd)
+	fn, ok := m.handlers[name]
+	m.mu.Unlock()
+
+	if ok {
+		return fn(args)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch name {
+	case "version":
+		return []string{"OK" + m.version}
+	case "mode":
+		if args == "" {
+			return []string{fmt.Sprintf("OK%d", m.mode)}
+		}
+		if args != "0" && args != "1" {
+			return []string{"ERROR-1"}
+		}
+		m.mode = int(args[0] - '0')
+		return []string{"OK"}
+	case "band":
+		if args == "" {
+			return []string{"OK" + m.band}
+		}
+		m.band = args
+		return []string{"OK"}
+	case "dr":
+		if args == "" {
+			return []string{"OK" + m.dr}
+		}
+		m.dr = args
+		return []string{"OK"}
+	case "get_config":
+		if args == "ch_list" {
+			return m.channelList()
+		}
+		value, ok := m.config[args]
+		if !ok {
+			return []string{"ERROR-2"}
+		}
+		return []string{"OK" + value}
+	case "set_config":
+		// every pair is checked before any is applied
+		pairs := strings.Split(args, "&")
+		for _, kv := range pairs {
+			key, value, ok := strings.Cut(kv, ":")
+			if !ok {
+				return []string{"ERROR-1"}
+			}
+			if _, _, ok := m.parseChannelMask(value); key == "ch_mask" && !ok {
+				return []string{"ERROR-1"}
+			}
+		}
+		for _, kv := range pairs {
+			key, value, _ := strings.Cut(kv, ":")
+			if key == "ch_mask" {
+				m.setChannelMask(value)
+			}
+			m.config[key] = value
+		}
+		return []string{"OK"}
+	case "join":
+		if m.mode != 0 {
+			return []string{"ERROR-1"}
+		}
+		m.joined = m.join == "at+recv=3,0,0"
+		return []string{"OK", m.join}
+	case "send":
+		return m.send(args)
+	case "signal":
+		return []string{"OK-40,7"}
+	case "status":
+		if args == "0" {
+			return []string{"OK"}
+		}
+		return []string{"OK0,0,0,0,0,0,0"}
+	case "rf_config":
+		if args == "" {
+			return []string{"OK" + m.rfConfig}
+		}
+		m.rfConfig = args
+		return []string{"OK"}
+	case "txc":
+		if m.mode != 1 {
+			return []string{"ERROR-1"}
+		}
+		return []string{"OK", "at+recv=9,0,0"}
+	case "rxc", "rx_stop", "tx_stop", "recv_ex", "reload", "reset", "sleep", "uart", "link_cnt", "abp_info":
+		return []string{"OK"}
+	}
+	return []string{"ERROR-1"}
+}
+
+// send answers at+send=<type>,<port>,<data>.
+func (m *Module) send(args string) []string {
+	fields := strings.Split(args, ",")
+	if len(fields) != 3 || len(fields[2])%2 != 0 {
+		return []string{"ERROR-1"}
+	}
+	if !m.joined {
+		return []string{"ERROR-5"}
+	}
+
+	if len(m.downlinks) > 0 {
+		d := m.downlinks[0]
+		m.downlinks = m.downlinks[1:]
+		return []string{"OK", fmt.Sprintf("at+recv=0,%d,%d,%d,%d:%X", d.Port, d.RSSI, d.SNR, len(d.Payload), d.Payload)}
+	}
+	if fields[0] == "1" {
+		return []string{"OK", "at+recv=1,0,0"}
+	}
+	return []string{"OK", "at+recv=2,0,0"}
+}
+
+// plan first channel, spacing and count of the 125 kHz channels then of
+// the 500 kHz channels of the bands with channel masks.
+var plans = map[string][2][3]int{
+	"US915": {{902300000, 200000, 64}, {903000000, 1600000, 8}},
+	"AU915": {{915200000, 200000, 64}, {915900000, 1600000, 8}},
+}
+
+// defaultChannels channels of the other bands.
+var defaultChannels = map[string][]int{
+	"EU868": {868100000, 868300000, 868500000},
+}
+
+// parseChannelMask parses ch_mask:<word>,<hex mask>, only accepted by
+// the bands with channel masks.
+func (m *Module) parseChannelMask(value string) (int, uint16, bool) {
+	var word int
+	var mask uint16
+	if _, err := fmt.Sscanf(value, "%d,%X", &word, &mask); err != nil || word < 0 || word > 4 {
+		return 0, 0, false
+	}
+	if _, ok := plans[m.band]; !ok {
+		return 0, 0, false
+	}
+	return word, mask, true
+}
+
+// setChannelMask applies ch_mask:<word>,<hex mask>.
+func (m *Module) setChannelMask(value string) {
+	word, mask, ok := m.parseChannelMask(value)
+	if !ok {
+		return
+	}
+	m.initChannels()
+	for i := 0; i < 16; i++ {
+		if ch := word*16 + i; ch < len(m.channels) {
+			m.channels[ch] = mask&(1<<i) != 0
+		}
+	}
+}
+
+// channelList answers get_config=ch_list.
+func (m *Module) channelList() []string {
+	var entries []string
+	if plan, ok := plans[m.band]; ok {
+		m.initChannels()
+		ch := 0
+		for j, p := range plan {
+			minDR, maxDR := 0, 3
+			if m.band == "AU915" {
+				maxDR = 5
+			}
+			if j == 1 {
+				minDR, maxDR = maxDR+1, maxDR+1
+			}
+			for i := 0; i < p[2]; i++ {
+				if m.channels[ch] {
+					entries = append(entries, fmt.Sprintf("%d,on,%d,%d,%d", ch, p[0]+i*p[1], minDR, maxDR))
+				} else {
+					entries = append(entries, fmt.Sprintf("%d,off", ch))
+				}
+				ch++
+			}
+		}
+	} else if freqs, ok := defaultChannels[m.band]; ok {
+		for i, f := range freqs {
+			entries = append(entries, fmt.Sprintf("%d,on,%d,0,5", i, f))
+		}
+	} else {
+		return []string{"ERROR-12"}
+	}
+	return []string{"OK" + strings.Join(entries, ";")}
+}
+
+// initChannels enables every channel of the band the first time.
+func (m *Module) initChannels() {
+	if m.channels == nil {
+		m.channels = make([]bool, 72)
+		for i := range m.channels {
+			m.channels[i] = true
+		}
+	}
+}
+
+// session a port connected to the module.
+type session struct {
+	mu   sync.Mutex
+	conn io.Writer
+}
+
+func (s *session) write(lines []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, line := range lines {
+		if _, err := io.WriteString(s.conn, line+crlf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// port in-memory serial port, commands are answered as soon as they are
+// written.
+type port struct {
+	module *Module
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	in     []byte
+	out    []byte
+	closed bool
+}
+
+func (p *port) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.out) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := copy(b, p.out)
+	p.out = p.out[n:]
+	return n, nil
+}
+
+func (p *port) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	p.in = append(p.in, b...)
+
+	var commands []string
+	for {
+		i := strings.IndexAny(string(p.in), "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(p.in[:i])); line != "" {
+			commands = append(commands, line)
+		}
+		p.in = p.in[i+1:]
+	}
+	p.mu.Unlock()
+
+	for _, cmd := range commands {
+		if err := p.write(p.module.Handle(cmd)); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (p *port) write(lines []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return io.ErrClosedPipe
+	}
+	for _, line := range lines {
+		p.out = append(p.out, line+crlf...)
+	}
+	p.cond.Broadcast()
+	return nil
+}
+
+func (p *port) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.module.mu.Lock()
+	delete(p.module.sessions, p)
+	p.module.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,96 @@
+package simulator
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestModule_Handle(t *testing.T) {
+	m := New()
+
+	tests := []struct {
+		in  string
+		out []string
+	}{
+		{"at+version", []string{"OK2.0.3.0"}},
+		{"at+get_config=dev_eui", []string{"OK0102030405060708"}},
+		{"at+send=0,2,0102", []string{"ERROR-5"}},
+		{"at+join=otaa", []string{"OK", "at+recv=3,0,0"}},
+		{"at+send=0,2,0102", []string{"OK", "at+recv=2,0,0"}},
+		{"at+send=1,2,0102", []string{"OK", "at+recv=1,0,0"}},
+		{"at+txc=1,0,0102", []string{"ERROR-1"}},
+		{"at+mode=1", []string{"OK"}},
+		{"at+txc=1,0,0102", []string{"OK", "at+recv=9,0,0"}},
+		{"at+unknown", []string{"ERROR-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			actual := m.Handle(tt.in)
+			if strings.Join(actual, "|") != strings.Join(tt.out, "|") {
+				t.Errorf("got %q, want %q", actual, tt.out)
+			}
+		})
+	}
+}
+
+func TestModule_ChannelMask(t *testing.T) {
+	m := New()
+	if out := m.Handle("at+set_config=ch_mask:0,FF00"); out[0] != "ERROR-1" {
+		t.Errorf("got %q for EU868", out)
+	}
+	if out := m.Handle("at+get_config=ch_list"); out[0] != "OK0,on,868100000,0,5;1,on,868300000,0,5;2,on,868500000,0,5" {
+		t.Errorf("got %q", out)
+	}
+
+	m.Handle("at+band=US915")
+	for _, cmd := range []string{"at+set_config=ch_mask:0,FF00", "at+set_config=ch_mask:4,0002"} {
+		if out := m.Handle(cmd); out[0] != "OK" {
+			t.Fatalf("got %q", out)
+		}
+	}
+	// a failing pair leaves the mask as it was
+	if out := m.Handle("at+set_config=ch_mask:0,0000&ch_mask:5,0001"); out[0] != "ERROR-1" {
+		t.Errorf("got %q", out)
+	}
+	out := m.Handle("at+get_config=ch_list")
+	entries := strings.Split(strings.TrimPrefix(out[0], "OK"), ";")
+	if len(entries) != 72 || entries[0] != "0,off" || entries[8] != "8,on,903900000,0,3" || entries[65] != "65,on,904600000,4,4" || entries[64] != "64,off" {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestModule_QueueDownlink(t *testing.T) {
+	m := New()
+	m.Handle("at+join=otaa")
+	m.QueueDownlink(Downlink{Port: 3, RSSI: -50, SNR: 8, Payload: []byte{0xca, 0xfe}})
+
+	actual := m.Handle("at+send=0,2,01")
+	if len(actual) != 2 || actual[1] != "at+recv=0,3,-50,8,2:CAFE" {
+		t.Errorf("got %q", actual)
+	}
+}
+
+func TestModule_Port(t *testing.T) {
+	m := New()
+	p := m.Port()
+	defer p.Close()
+
+	if _, err := io.WriteString(p, "at+version\r\n"); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	m.Emit("at+recv=8,0,0")
+
+	r := bufio.NewReader(p)
+	for _, want := range []string{"OK2.0.3.0\r\n", "at+recv=8,0,0\r\n"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if line != want {
+			t.Errorf("got %q, want %q", line, want)
+		}
+	}
+}
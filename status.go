@@ -0,0 +1,78 @@
+package rak811
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Signal quality of the last packet received, as reported by at+signal.
+type Signal struct {
+	RSSI int
+	SNR  int
+}
+
+// RadioStatus radio statistics, as reported by at+status.
+type RadioStatus struct {
+	TxSuccess int
+	TxError   int
+	RxSuccess int
+	RxTimeout int
+	RxError   int
+	RSSI      int
+	SNR       int
+}
+
+// ParseSignal parses the at+signal response, with or without the OK
+// prefix.
+// Format: <rssi>,<snr>
+func ParseSignal(resp string) (Signal, error) {
+	values, err := parseInts(resp, 2)
+	if err != nil {
+		return Signal{}, fmt.Errorf("invalid signal %q: %v", resp, err)
+	}
+	return Signal{RSSI: values[0], SNR: values[1]}, nil
+}
+
+// ParseRadioStatus parses the at+status response, with or without the OK
+// prefix.
+// Format: <tx ok>,<tx err>,<rx ok>,<rx timeout>,<rx err>,<rssi>,<snr>
+func ParseRadioStatus(resp string) (RadioStatus, error) {
+	values, err := parseInts(resp, 7)
+	if err != nil {
+		return RadioStatus{}, fmt.Errorf("invalid radio status %q: %v", resp, err)
+	}
+	return RadioStatus{
+		TxSuccess: values[0],
+		TxError:   values[1],
+		RxSuccess: values[2],
+		RxTimeout: values[3],
+		RxError:   values[4],
+		RSSI:      values[5],
+		SNR:       values[6],
+	}, nil
+}
+
+// ErrorCode returns the module error code of err, either a LoraError or
+// the ERROR-n reply returned as an error.
+func ErrorCode(err error) (int, bool) {
+	return errorCode("", err)
+}
+
+// parseInts parses n comma separated integers.
+func parseInts(resp string, n int) ([]int, error) {
+	fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(resp), OK), ",")
+	if len(fields) != n {
+		return nil, fmt.Errorf("got %d fields, want %d", len(fields), n)
+	}
+
+	values := make([]int, n)
+	for i, f := range fields {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
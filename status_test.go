@@ -0,0 +1,56 @@
+package rak811
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	s, err := ParseSignal("OK-40,7")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if s != (Signal{RSSI: -40, SNR: 7}) {
+		t.Errorf("got %+v", s)
+	}
+
+	for _, resp := range []string{"OK", "OK-40", "OK-40,x", "OK1,2,3"} {
+		if _, err := ParseSignal(resp); err == nil {
+			t.Errorf("%q: expected error", resp)
+		}
+	}
+}
+
+func TestParseRadioStatus(t *testing.T) {
+	s, err := ParseRadioStatus("OK5,1,3,2,0,-98,-3")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	want := RadioStatus{TxSuccess: 5, TxError: 1, RxSuccess: 3, RxTimeout: 2, RSSI: -98, SNR: -3}
+	if s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+
+	if _, err := ParseRadioStatus("OK5,1,3"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		ok   bool
+	}{
+		{WhichError("ERROR-5"), CodeNotJoin, true},
+		{errors.New("ERROR-6"), CodeMacBusyErr, true},
+		{ErrDisconnected, 0, false},
+	}
+
+	for _, tt := range tests {
+		code, ok := ErrorCode(tt.err)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("%v: got %d %v, want %d %v", tt.err, code, ok, tt.code, tt.ok)
+		}
+	}
+}
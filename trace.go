@@ -0,0 +1,135 @@
+package rak811
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName instrumentation scope of the spans.
+const tracerName = "github.com/calvernaz/rak811"
+
+// Span attributes.
+const (
+	AttrCommand      = attribute.Key("rak811.command")
+	AttrBytesWritten = attribute.Key("rak811.bytes_written")
+	AttrBytesRead    = attribute.Key("rak811.bytes_read")
+	AttrReplyStatus  = attribute.Key("rak811.reply.status")
+	AttrEventStatus  = attribute.Key("rak811.event.status")
+	AttrErrorCode    = attribute.Key("rak811.error_code")
+)
+
+// Reply statuses, the value of AttrReplyStatus.
+const (
+	ReplyOK    = "ok"
+	ReplyError = "error"
+	ReplyEvent = "event"
+)
+
+// newTracer returns the tracer of the config, or one recording nothing.
+func newTracer(conf *Config) trace.Tracer {
+	if conf != nil && conf.TracerProvider != nil {
+		return conf.TracerProvider.Tracer(tracerName)
+	}
+	return noop.NewTracerProvider().Tracer(tracerName)
+}
+
+// exchange span of a command exchange, the parent of the spans of the
+// lines read.
+type exchange struct {
+	ctx     context.Context
+	span    trace.Span
+	written int64
+	lines   *lineScanner
+	read    int64
+}
+
+// WithContext runs fn with the spans of the commands it sends children of
+// the span of ctx, e.g. of the request being served. Otherwise they are
+// root spans.
+func (l *Lora) WithContext(ctx context.Context, fn func(l *Lora) error) error {
+	parent := l.ctx
+	l.ctx = ctx
+	defer func() { l.ctx = parent }()
+	return fn(l)
+}
+
+// startExchange starts the span of a command exchange, a child of the
+// span of the WithContext context if any.
+func (l *Lora) startExchange(cmd string) {
+	parent := l.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, span := l.tracer.Start(parent, "rak811 "+commandName(cmd),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrCommand.String(redact(cmd))),
+	)
+	_, lines := l.conn()
+	l.exchange = &exchange{
+		ctx:     ctx,
+		span:    span,
+		written: l.written,
+		lines:   lines,
+		read:    lines.read.Load(),
+	}
+}
+
+// endExchange ends the span of the current command exchange.
+func (l *Lora) endExchange(resp string, err error) {
+	x := l.exchange
+	if x == nil {
+		return
+	}
+	l.exchange = nil
+
+	x.span.SetAttributes(
+		AttrBytesWritten.Int64(l.written-x.written),
+		AttrBytesRead.Int64(x.lines.read.Load()-x.read),
+	)
+	endSpan(x.span, resp, err)
+}
+
+// startLine starts the span of a line read during a command exchange,
+// nil outside of an exchange.
+func (l *Lora) startLine() trace.Span {
+	if l.exchange == nil {
+		return nil
+	}
+	_, span := l.tracer.Start(l.exchange.ctx, "rak811 line")
+	return span
+}
+
+// endSpan records the reply on the span and ends it.
+func endSpan(span trace.Span, resp string, err error) {
+	if span == nil {
+		return
+	}
+
+	switch {
+	case isError(resp) != nil || err != nil:
+		span.SetAttributes(AttrReplyStatus.String(ReplyError))
+	case WhichEventResponse(resp) != nil:
+		span.SetAttributes(
+			AttrReplyStatus.String(ReplyEvent),
+			AttrEventStatus.Int(WhichEventResponse(resp).Code()),
+		)
+	case isOk(resp):
+		span.SetAttributes(AttrReplyStatus.String(ReplyOK))
+	}
+
+	code, failed := errorCode(resp, err)
+	if failed {
+		span.SetAttributes(AttrErrorCode.Int(code))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if failed {
+		span.SetStatus(codes.Error, resp)
+	}
+	span.End()
+}
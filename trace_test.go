@@ -0,0 +1,168 @@
+package rak811
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestLora_Tracing(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	fsp := newFakeSerialConn(
+		[]byte("OK"+CrLf), []byte("OK"+CrLf+JoinSuccess+CrLf), []byte("ERROR-5"+CrLf),
+	)
+	lora, _ := newLora(fsp)
+	lora.tracer = tp.Tracer(tracerName)
+
+	if _, err := lora.SetConfig("app_key:a6b08140dae1d795ebfa5a6dee1f4dbd"); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := lora.JoinOTAA(); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := lora.Send("1,2,00"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// children end before their parent
+	spans := exp.GetSpans()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	want := []string{
+		"rak811 line", "rak811 set_config",
+		"rak811 line", "rak811 line", "rak811 join",
+		"rak811 line", "rak811 send",
+	}
+	if len(names) != len(want) {
+		t.Fatalf("got spans %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got spans %v, want %v", names, want)
+		}
+	}
+
+	set := spans[1]
+	if v := spanAttr(set, AttrCommand).AsString(); v != "set_config=app_key:<redacted>" {
+		t.Errorf("got command %q", v)
+	}
+	if v := spanAttr(set, AttrBytesWritten).AsInt64(); v != int64(len("at+set_config=app_key:a6b08140dae1d795ebfa5a6dee1f4dbd\r\n")) {
+		t.Errorf("got %d bytes written", v)
+	}
+	if v := spanAttr(set, AttrBytesRead).AsInt64(); v != 4 {
+		t.Errorf("got %d bytes read", v)
+	}
+
+	join := spans[4]
+	for _, child := range spans[2:4] {
+		if child.Parent.SpanID() != join.SpanContext.SpanID() {
+			t.Errorf("span %s isn't a child of join", child.Name)
+		}
+	}
+	if v := spanAttr(spans[3], AttrEventStatus).AsInt64(); v != StatusJoinedSuccess {
+		t.Errorf("got event status %d", v)
+	}
+	if v := spanAttr(join, AttrReplyStatus).AsString(); v != ReplyEvent {
+		t.Errorf("got reply status %q", v)
+	}
+
+	send := spans[6]
+	if v := spanAttr(send, AttrErrorCode).AsInt64(); v != CodeNotJoin {
+		t.Errorf("got error code %d", v)
+	}
+	if send.Status.Code != codes.Error {
+		t.Errorf("got status %v", send.Status)
+	}
+}
+
+func TestLora_TracingWithContext(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	fsp := newFakeSerialConn([]byte("OK"+CrLf), []byte("OK"+CrLf))
+	lora, _ := newLora(fsp)
+	lora.tracer = tp.Tracer(tracerName)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if err := lora.WithContext(ctx, func(l *Lora) error {
+		_, err := l.Version()
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+	if _, err := lora.Version(); err != nil {
+		t.Fatal(err)
+	}
+
+	// line, version, request, then a root version span
+	spans := exp.GetSpans()
+	if len(spans) != 5 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	request := spans[2].SpanContext
+	if version := spans[1]; version.Parent.SpanID() != request.SpanID() || version.SpanContext.TraceID() != request.TraceID() {
+		t.Errorf("span %s isn't a child of the request", version.Name)
+	}
+	if version := spans[4]; version.Parent.IsValid() {
+		t.Errorf("span %s has parent %s after WithContext returned", version.Name, version.Parent.SpanID())
+	}
+}
+
+func TestP2P_Tracing(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	module := newFakeModule(func(cmd string) []string {
+		if strings.HasPrefix(cmd, "txc=") {
+			return []string{OK, "at+recv=9,0,0"}
+		}
+		return []string{OK}
+	})
+	lora, _ := newLora(module)
+	lora.tracer = tp.Tracer(tracerName)
+
+	p2p, err := NewP2P(lora, DefaultRFConfig)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p2p.Transmit(ctx, []byte{0xde, 0xad}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if err := p2p.Close(); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	// the commands of the link are exchanged as the others
+	var names []string
+	for _, s := range exp.GetSpans() {
+		if s.Name != "rak811 line" {
+			names = append(names, s.Name)
+		}
+	}
+	want := []string{"rak811 mode", "rak811 rf_config", "rak811 rxc", "rak811 txc", "rak811 rxc", "rak811 rx_stop"}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Errorf("got spans %v, want %v", names, want)
+	}
+}
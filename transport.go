@@ -0,0 +1,408 @@
+package rak811
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tarm/serial"
+)
+
+const (
+	// SchemeTCP raw TCP socket, e.g. ser2net in raw mode.
+	SchemeTCP = "tcp"
+	// SchemeRFC2217 telnet COM port control server, e.g. ser2net in telnet mode.
+	SchemeRFC2217 = "rfc2217"
+
+	dialTimeout = 10 * time.Second
+)
+
+// errPortClosed the port has been closed.
+var errPortClosed = errors.New("port closed")
+
+// openPort opens the transport selected by the config name, recording
+// its traffic if a capture is configured.
+func openPort(conf *Config) (io.ReadWriteCloser, error) {
+	p, err := dialPort(conf)
+	if err != nil {
+		return nil, err
+	}
+	if conf.Capture != nil {
+		return NewRecorder(p, conf.Capture), nil
+	}
+	return p, nil
+}
+
+// dialPort opens the transport selected by the config name: a serial
+// device path, tcp://host:port, rfc2217://host:port or replay://file.
+func dialPort(conf *Config) (io.ReadWriteCloser, error) {
+	u, err := url.Parse(conf.Name)
+	if err == nil {
+		switch u.Scheme {
+		case SchemeReplay:
+			return OpenReplay(strings.TrimPrefix(conf.Name, SchemeReplay+"://"))
+		case SchemeTCP:
+			return newNetPort(conf, func() (io.ReadWriteCloser, net.Conn, error) {
+				conn, err := net.DialTimeout("tcp", u.Host, dialTimeout)
+				return conn, conn, err
+			})
+		case SchemeRFC2217:
+			return newNetPort(conf, func() (io.ReadWriteCloser, net.Conn, error) {
+				conn, err := net.DialTimeout("tcp", u.Host, dialTimeout)
+				if err != nil {
+					return nil, nil, err
+				}
+				t := newTelnetConn(conn)
+				if err := t.negotiate(conf); err != nil {
+					conn.Close()
+					return nil, nil, err
+				}
+				return t, conn, nil
+			})
+		}
+	}
+
+	return openSerial(&serial.Config{
+		Name:        conf.Name,
+		Baud:        conf.Baud,
+		ReadTimeout: conf.Timeout,
+		Size:        conf.Size,
+		Parity:      serial.Parity(conf.Parity),
+		StopBits:    serial.StopBits(conf.StopBits),
+	})
+}
+
+// isSerial reports whether the config name is a local serial device.
+func isSerial(name string) bool {
+	u, err := url.Parse(name)
+	return err != nil || (u.Scheme != SchemeTCP && u.Scheme != SchemeRFC2217 && u.Scheme != SchemeReplay)
+}
+
+// netPort network connection behaving like a serial port: reads time out
+// with io.EOF. A broken connection fails the pending read, and is dialled
+// again on the next read or write.
+type netPort struct {
+	dial    func() (io.ReadWriteCloser, net.Conn, error)
+	timeout time.Duration
+
+	mu     sync.Mutex
+	rw     io.ReadWriteCloser
+	conn   net.Conn
+	closed bool
+}
+
+func newNetPort(conf *Config, dial func() (io.ReadWriteCloser, net.Conn, error)) (*netPort, error) {
+	p := &netPort{
+		dial:    dial,
+		timeout: conf.Timeout,
+	}
+	if _, _, err := p.get(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// get returns the current connection, dialling a new one if needed.
+func (p *netPort) get() (io.ReadWriteCloser, net.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, nil, errPortClosed
+	}
+	if p.rw == nil {
+		rw, conn, err := p.dial()
+		if err != nil {
+			return nil, nil, err
+		}
+		p.rw, p.conn = rw, conn
+	}
+	return p.rw, p.conn, nil
+}
+
+// drop closes a broken connection so the next operation dials again.
+func (p *netPort) drop(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == conn {
+		p.rw.Close()
+		p.rw, p.conn = nil, nil
+	}
+}
+
+func (p *netPort) Read(b []byte) (int, error) {
+	rw, conn, err := p.get()
+	if err != nil {
+		return 0, err
+	}
+
+	if p.timeout > 0 {
+		if err := conn.SetReadDeadline(time.Now().Add(p.timeout)); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rw.Read(b)
+	if err != nil {
+		if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+			// same as a serial read timeout
+			return n, io.EOF
+		}
+		// reconnect on the next read or write
+		p.drop(conn)
+		if n > 0 {
+			return n, nil
+		}
+		return 0, fmt.Errorf("connection lost: %v", err)
+	}
+	return n, nil
+}
+
+func (p *netPort) Write(b []byte) (int, error) {
+	rw, conn, err := p.get()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := rw.Write(b)
+	if err == nil {
+		return n, nil
+	}
+
+	// retry once on a new connection
+	p.drop(conn)
+	if rw, _, err = p.get(); err != nil {
+		return 0, err
+	}
+	return rw.Write(b)
+}
+
+func (p *netPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	if p.rw != nil {
+		return p.rw.Close()
+	}
+	return nil
+}
+
+// Telnet protocol bytes, RFC 854, and COM port control options, RFC 2217.
+const (
+	telnetIAC  = 255
+	telnetDONT = 254
+	telnetDO   = 253
+	telnetWONT = 252
+	telnetWILL = 251
+	telnetSB   = 250
+	telnetSE   = 240
+
+	telnetOptBinary  = 0
+	telnetOptSGA     = 3
+	telnetOptComPort = 44
+
+	comPortSetBaud     = 1
+	comPortSetDataSize = 2
+	comPortSetParity   = 3
+	comPortSetStopSize = 4
+)
+
+type telnetState int
+
+const (
+	telnetData telnetState = iota
+	telnetCommand
+	telnetOption
+	telnetSubneg
+	telnetSubnegIAC
+)
+
+// telnetConn strips the telnet commands from the stream and escapes the
+// data written, answering option negotiation.
+type telnetConn struct {
+	conn io.ReadWriteCloser
+
+	wmu sync.Mutex // writes from Write and the negotiation replies
+
+	state  telnetState
+	cmd    byte
+	subneg []byte
+
+	// onSubneg called with every subnegotiation received.
+	onSubneg func(opt byte, data []byte)
+}
+
+func newTelnetConn(conn io.ReadWriteCloser) *telnetConn {
+	return &telnetConn{conn: conn}
+}
+
+// negotiate enables the COM port option and sets the serial parameters.
+func (t *telnetConn) negotiate(conf *Config) error {
+	baud := make([]byte, 4)
+	binary.BigEndian.PutUint32(baud, uint32(conf.Baud))
+
+	msg := []byte{
+		telnetIAC, telnetWILL, telnetOptComPort,
+		telnetIAC, telnetWILL, telnetOptBinary,
+		telnetIAC, telnetDO, telnetOptBinary,
+		telnetIAC, telnetDO, telnetOptSGA,
+	}
+	msg = append(msg, subnegotiation(comPortSetBaud, baud...)...)
+	msg = append(msg, subnegotiation(comPortSetDataSize, conf.Size)...)
+	msg = append(msg, subnegotiation(comPortSetParity, comPortParity(conf.Parity))...)
+	msg = append(msg, subnegotiation(comPortSetStopSize, comPortStopSize(conf.StopBits))...)
+
+	return t.writeRaw(msg)
+}
+
+func subnegotiation(cmd byte, value ...byte) []byte {
+	msg := []byte{telnetIAC, telnetSB, telnetOptComPort, cmd}
+	for _, b := range value {
+		msg = append(msg, b)
+		if b == telnetIAC {
+			msg = append(msg, telnetIAC)
+		}
+	}
+	return append(msg, telnetIAC, telnetSE)
+}
+
+func comPortParity(p Parity) byte {
+	switch p {
+	case ParityOdd:
+		return 2
+	case ParityEven:
+		return 3
+	case ParityMark:
+		return 4
+	case ParitySpace:
+		return 5
+	}
+	return 1
+}
+
+func comPortStopSize(s StopBits) byte {
+	switch s {
+	case Stop2:
+		return 2
+	case Stop1Half:
+		return 3
+	}
+	return 1
+}
+
+func (t *telnetConn) writeRaw(b []byte) error {
+	t.wmu.Lock()
+	defer t.wmu.Unlock()
+
+	_, err := t.conn.Write(b)
+	return err
+}
+
+func (t *telnetConn) Write(b []byte) (int, error) {
+	escaped := make([]byte, 0, len(b))
+	for _, c := range b {
+		escaped = append(escaped, c)
+		if c == telnetIAC {
+			escaped = append(escaped, telnetIAC)
+		}
+	}
+
+	if err := t.writeRaw(escaped); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (t *telnetConn) Read(b []byte) (int, error) {
+	buf := make([]byte, len(b))
+	for {
+		n, err := t.conn.Read(buf)
+		data := t.decode(buf[:n])
+		copy(b, data)
+		if len(data) > 0 || err != nil {
+			return len(data), err
+		}
+	}
+}
+
+// decode strips the telnet commands, data is decoded in place.
+func (t *telnetConn) decode(buf []byte) []byte {
+	data := buf[:0]
+	for _, c := range buf {
+		switch t.state {
+		case telnetData:
+			if c == telnetIAC {
+				t.state = telnetCommand
+				continue
+			}
+			data = append(data, c)
+		case telnetCommand:
+			switch c {
+			case telnetIAC:
+				data = append(data, c)
+				t.state = telnetData
+			case telnetDO, telnetDONT, telnetWILL, telnetWONT:
+				t.cmd = c
+				t.state = telnetOption
+			case telnetSB:
+				t.subneg = t.subneg[:0]
+				t.state = telnetSubneg
+			default:
+				t.state = telnetData
+			}
+		case telnetOption:
+			t.reply(t.cmd, c)
+			t.state = telnetData
+		case telnetSubneg:
+			if c == telnetIAC {
+				t.state = telnetSubnegIAC
+				continue
+			}
+			t.subneg = append(t.subneg, c)
+		case telnetSubnegIAC:
+			switch c {
+			case telnetSE:
+				if t.onSubneg != nil && len(t.subneg) > 0 {
+					t.onSubneg(t.subneg[0], append([]byte(nil), t.subneg[1:]...))
+				}
+				t.state = telnetData
+			default:
+				t.subneg = append(t.subneg, c)
+				t.state = telnetSubneg
+			}
+		}
+	}
+	return data
+}
+
+// reply refuses the options not supported, the supported ones were
+// already requested so they aren't acknowledged again.
+func (t *telnetConn) reply(cmd, opt byte) {
+	supported := opt == telnetOptBinary || opt == telnetOptSGA || opt == telnetOptComPort
+	if supported {
+		return
+	}
+
+	switch cmd {
+	case telnetDO:
+		_ = t.writeRaw([]byte{telnetIAC, telnetWONT, opt})
+	case telnetWILL:
+		_ = t.writeRaw([]byte{telnetIAC, telnetDONT, opt})
+	}
+}
+
+func (t *telnetConn) Close() error {
+	return t.conn.Close()
+}
@@ -0,0 +1,158 @@
+package rak811
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvernaz/rak811/simulator"
+)
+
+func TestNew_TCP(t *testing.T) {
+	module := simulator.New()
+	l, conns := listen(t, func(c net.Conn) io.ReadWriteCloser { return c }, module)
+	defer l.Close()
+
+	connected := make(chan struct{}, 1)
+	lora, err := New(&Config{
+		Name:             "tcp://" + l.Addr().String(),
+		Timeout:          100 * time.Millisecond,
+		ReconnectBackoff: 10 * time.Millisecond,
+		OnStateChange: func(state ConnState, err error) {
+			if state == StateConnected {
+				connected <- struct{}{}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer lora.Close()
+
+	if resp, err := lora.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Fatalf("got %q, %v", resp, err)
+	}
+
+	// the connection breaks, the pending command fails and the port is
+	// reopened on a new connection
+	conns.closeAll()
+	if _, err := lora.Version(); err == nil {
+		t.Fatal("want error on broken connection")
+	}
+	select {
+	case <-connected:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reconnection")
+	}
+	if resp, err := lora.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Fatalf("got %q, %v after reconnecting", resp, err)
+	}
+	if conns.count() != 2 {
+		t.Errorf("got %d connections, want 2", conns.count())
+	}
+}
+
+func TestNew_RFC2217(t *testing.T) {
+	module := simulator.New()
+
+	var mu sync.Mutex
+	settings := make(map[byte][]byte)
+	l, _ := listen(t, func(c net.Conn) io.ReadWriteCloser {
+		tc := newTelnetConn(c)
+		tc.onSubneg = func(opt byte, data []byte) {
+			if opt == telnetOptComPort && len(data) > 0 {
+				mu.Lock()
+				settings[data[0]] = data[1:]
+				mu.Unlock()
+			}
+		}
+		return tc
+	}, module)
+	defer l.Close()
+
+	lora, err := New(&Config{Name: "rfc2217://" + l.Addr().String(), Baud: 9600})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	defer lora.Close()
+
+	if resp, err := lora.Version(); err != nil || resp != "OK2.0.3.0" {
+		t.Fatalf("got %q, %v", resp, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if baud := settings[comPortSetBaud]; len(baud) != 4 || binary.BigEndian.Uint32(baud) != 9600 {
+		t.Errorf("got baud %v, want 9600", baud)
+	}
+	if size := settings[comPortSetDataSize]; !bytes.Equal(size, []byte{8}) {
+		t.Errorf("got data size %v, want 8", size)
+	}
+}
+
+func TestTelnetConn_Decode(t *testing.T) {
+	var written bytes.Buffer
+	tc := newTelnetConn(nopCloser{&written})
+
+	in := []byte{'O', telnetIAC, telnetIAC, telnetIAC, telnetDO, 24, 'K', telnetIAC, telnetSB, telnetOptComPort, 101, 0, telnetIAC, telnetSE, '\r', '\n'}
+	out := tc.decode(in)
+	if !bytes.Equal(out, []byte{'O', telnetIAC, 'K', '\r', '\n'}) {
+		t.Errorf("got %v", out)
+	}
+	// unsupported option refused
+	if !bytes.Equal(written.Bytes(), []byte{telnetIAC, telnetWONT, 24}) {
+		t.Errorf("got reply %v", written.Bytes())
+	}
+}
+
+type nopCloser struct {
+	io.ReadWriter
+}
+
+func (nopCloser) Close() error { return nil }
+
+type connSet struct {
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (c *connSet) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+}
+
+func (c *connSet) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.conns)
+}
+
+// listen serves the simulated module on a local TCP listener.
+func listen(t *testing.T, wrap func(net.Conn) io.ReadWriteCloser, module *simulator.Module) (net.Listener, *connSet) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	conns := &connSet{}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conns.mu.Lock()
+			conns.conns = append(conns.conns, conn)
+			conns.mu.Unlock()
+			go module.ServeConn(wrap(conn))
+		}
+	}()
+	return l, conns
+}